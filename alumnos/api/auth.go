@@ -0,0 +1,156 @@
+package api
+
+import (
+	"alumnos/auth"
+	"alumnos/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (api *API) Login(w http.ResponseWriter, r *http.Request) {
+	var input models.LoginRequest
+	if !api.decodeJSON(w, r, &input) {
+		return
+	}
+
+	// Validar campos requeridos
+	if input.Email == "" || input.Password == "" {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Los campos 'email' y 'password' son obligatorios")
+		return
+	}
+
+	// Mismo mensaje y mismo tiempo para correo inexistente y contraseña incorrecta
+	teacher, err := api.Repo.GetTeacherByEmail(r.Context(), input.Email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		writeRepoError(w, r, err, "Error al iniciar sesión")
+		return
+	}
+	hash := auth.DummyHash
+	if err == nil {
+		hash = teacher.Password
+	}
+	if !auth.CheckPassword(hash, input.Password) || err != nil {
+		writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "Credenciales inválidas")
+		return
+	}
+
+	api.writeToken(w, r, teacher.ID, teacher.Role)
+}
+
+func (api *API) LoginAlumno(w http.ResponseWriter, r *http.Request) {
+	var input models.LoginRequest
+	if !api.decodeJSON(w, r, &input) {
+		return
+	}
+
+	// Validar campos requeridos
+	if input.Email == "" || input.Password == "" {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Los campos 'email' y 'password' son obligatorios")
+		return
+	}
+
+	credentials, err := api.Repo.GetAlumnCredentialsByEmail(r.Context(), input.Email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		writeRepoError(w, r, err, "Error al iniciar sesión")
+		return
+	}
+	hash := auth.DummyHash
+	if err == nil {
+		hash = credentials.Password
+	}
+	if !auth.CheckPassword(hash, input.Password) || err != nil {
+		writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "Credenciales inválidas")
+		return
+	}
+
+	api.writeToken(w, r, credentials.ID, auth.RoleStudent)
+}
+
+func (api *API) writeToken(w http.ResponseWriter, r *http.Request, subject int, role string) {
+	token, expiresAt, err := api.Auth.IssueToken(subject, role)
+	if err != nil {
+		writeRepoError(w, r, err, "Error al generar token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.LoginResponse{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// RequireAuth rechaza las solicitudes sin un token Bearer válido.
+func (api *API) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "Se requiere autenticación")
+			return
+		}
+
+		claims, err := api.Auth.ParseToken(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, fmt.Sprintf("Token no válido: %v", err))
+			return
+		}
+
+		recordCaller(r.Context(), claims)
+
+		// El límite por usuario evita que un token repartido entre varias IP lo eluda
+		key := claims.Role + ":" + strconv.Itoa(claims.Subject)
+		if ok, retryAfter := api.UserLimiter.Allow(key); !ok {
+			writeRateLimited(w, r, retryAfter)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+	})
+}
+
+// RequireRole limita la ruta a los roles indicados. Debe ir dentro de RequireAuth.
+func (api *API) RequireRole(next http.Handler, roles ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := auth.ClaimsFromContext(r.Context())
+		if !ok || !claims.HasRole(roles...) {
+			writeError(w, r, http.StatusForbidden, CodeForbidden, fmt.Sprintf("Acceso denegado: se requiere el rol %s", strings.Join(roles, " o ")))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Protect exige un token válido y, si se indican roles, que el llamador tenga alguno.
+func (api *API) Protect(handler http.HandlerFunc, roles ...string) http.Handler {
+	if len(roles) == 0 {
+		return api.RequireAuth(handler)
+	}
+	return api.RequireAuth(api.RequireRole(handler, roles...))
+}
+
+// authorizeAlumn permite el acceso a los datos de un alumno solo al propio
+// alumno, a profesores y a administradores. Responde 403 si no está permitido.
+func authorizeAlumn(w http.ResponseWriter, r *http.Request, alumnID int) bool {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "Se requiere autenticación")
+		return false
+	}
+
+	if claims.Role == auth.RoleStudent && claims.Subject != alumnID {
+		writeError(w, r, http.StatusForbidden, CodeForbidden, "Acceso denegado: un alumno solo puede consultar sus propios datos")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"alumnos/auth"
+	"alumnos/catalog"
+	"alumnos/ratelimit"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestAPI arma la API sin base de datos; sirve para las rutas que
+// responden antes de llegar al repositorio.
+func newTestAPI(t *testing.T) *API {
+	t.Helper()
+	return NewAPI(nil, auth.NewManager("secreto de prueba", time.Hour), Options{
+		UserLimiter: ratelimit.New(1000, 1000),
+		Catalog:     catalog.New(),
+	})
+}
+
+func newTestMux(api *API) *http.ServeMux {
+	mux := http.NewServeMux()
+	RegisterRoutes(mux, api)
+	return mux
+}
+
+func bearer(t *testing.T, api *API, subject int, role string) string {
+	t.Helper()
+	return "Bearer " + mustToken(t, api.Auth, subject, role)
+}
+
+// errorCode devuelve el campo error.code de una respuesta de error.
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("la respuesta no es un ErrorResponse: %v", err)
+	}
+	return body.Error.Code
+}
+
+// Rutas que se pueden llamar sin token.
+var publicRoutes = map[string]bool{
+	"POST /v1/auth/login":           true,
+	"POST /v1/auth/alumnos/login":   true,
+	"GET /v1/courses":               true,
+	"GET /v1/courses/{id}/subjects": true,
+	"POST /v1/courses/subjects":     true,
+	"GET /v1/semesters":             true,
+	"GET /v1/periods":               true,
+	"GET /healthz":                  true,
+	"GET /readyz":                   true,
+	"GET /metrics":                  true,
+	"GET /openapi.json":             true,
+	"GET /docs":                     true,
+}
+
+func TestRoutesRequireToken(t *testing.T) {
+	api := newTestAPI(t)
+	mux := newTestMux(api)
+
+	for _, route := range Routes(api) {
+		if publicRoutes[route.Pattern] {
+			continue
+		}
+		t.Run(route.Pattern, func(t *testing.T) {
+			method, path, _ := strings.Cut(route.Pattern, " ")
+			path = strings.ReplaceAll(path, "{id}", "1")
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, http.StatusUnauthorized)
+			}
+			if rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("falta el header WWW-Authenticate")
+			}
+			if code := errorCode(t, rec); code != CodeUnauthorized {
+				t.Errorf("code = %q, se esperaba %q", code, CodeUnauthorized)
+			}
+		})
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	api := newTestAPI(t)
+	mux := newTestMux(api)
+	expired := mustToken(t, auth.NewManager("secreto de prueba", -time.Minute), 1, auth.RoleAdmin)
+
+	tests := []struct {
+		name          string
+		method, path  string
+		body          string
+		authorization string
+		wantStatus    int
+		wantCode      string
+	}{
+		{"token con otro secreto", "GET", "/v1/students",
+			"", "Bearer " + mustToken(t, auth.NewManager("otro", time.Hour), 1, auth.RoleAdmin),
+			http.StatusUnauthorized, CodeUnauthorized},
+		{"token expirado", "GET", "/v1/students", "", "Bearer " + expired,
+			http.StatusUnauthorized, CodeUnauthorized},
+		{"esquema distinto de Bearer", "GET", "/v1/students", "", "Basic dXNlcjpwYXNz",
+			http.StatusUnauthorized, CodeUnauthorized},
+		{"alumno lista alumnos", "GET", "/v1/students", "", bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+		{"alumno busca alumnos", "GET", "/v1/alumnos/search?q=ana", "", bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+		{"profesor registra alumno", "POST", "/v1/alumnos", "{}", bearer(t, api, 2, auth.RoleTeacher),
+			http.StatusForbidden, CodeForbidden},
+		{"profesor crea materia", "POST", "/v1/courses/1/subjects", "{}", bearer(t, api, 2, auth.RoleTeacher),
+			http.StatusForbidden, CodeForbidden},
+		{"alumno registra calificación", "POST", "/v1/calificaciones/parcial", "{}", bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+		{"alumno consulta a otro alumno", "GET", "/v1/alumnos/3", "", bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+		{"alumno consulta calificaciones de otro", "GET", "/v1/alumnos/3/calificaciones", "", bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+		{"alumno consulta semestres completados de otro", "GET", "/v1/alumnos/3/completed-semesters", "", bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+		{"alumno consulta semestres completados de otro (ruta antigua)", "POST", "/v1/completed-semesters", `{"alumn_id": 3}`, bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+		{"alumno consulta materias de otro (ruta antigua)", "POST", "/v1/semester-courses", `{"alumn_id": 3}`, bearer(t, api, 4, auth.RoleStudent),
+			http.StatusForbidden, CodeForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d (%s)", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if code := errorCode(t, rec); code != tt.wantCode {
+				t.Errorf("code = %q, se esperaba %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func mustToken(t *testing.T, manager *auth.Manager, subject int, role string) string {
+	t.Helper()
+	token, _, err := manager.IssueToken(subject, role)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	return token
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// catalogCacheControl deja que el navegador reutilice los catálogos unos
+// minutos; después revalida con If-None-Match y recibe 304 si no cambiaron.
+const catalogCacheControl = "public, max-age=300"
+
+// writeCatalog responde un catálogo desde api.Catalog. http.ServeContent se
+// encarga de If-None-Match, If-Modified-Since y de responder 304.
+func (api *API) writeCatalog(w http.ResponseWriter, r *http.Request, key string, load func(context.Context) (any, error), message string) {
+	entry, err := api.Catalog.Get(r.Context(), key, load)
+	if err != nil {
+		writeRepoError(w, r, err, message)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", entry.ETag)
+	w.Header().Set("Cache-Control", catalogCacheControl)
+	http.ServeContent(w, r, "", entry.LastModified, bytes.NewReader(entry.Body))
+}
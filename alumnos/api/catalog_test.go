@@ -0,0 +1,104 @@
+package api
+
+import (
+	"alumnos/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestWriteCatalog(t *testing.T) {
+	api := newTestAPI(t)
+	semesters := func(context.Context) (any, error) {
+		return []models.CatSemester{{ID: 1, Name: "Primer semestre"}}, nil
+	}
+
+	// Primera respuesta para conocer el ETag y la fecha
+	first := httptest.NewRecorder()
+	api.writeCatalog(first, httptest.NewRequest("GET", "/v1/semesters", nil), "semesters", semesters, "error")
+	etag := first.Header().Get("ETag")
+	lastModified, err := http.ParseTime(first.Header().Get("Last-Modified"))
+	if err != nil {
+		t.Fatalf("Last-Modified inválido: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		header     string
+		value      string
+		wantStatus int
+		wantBody   bool
+	}{
+		{"sin validadores", "GET", "", "", http.StatusOK, true},
+		{"HEAD", "HEAD", "", "", http.StatusOK, false},
+		{"If-None-Match igual", "GET", "If-None-Match", etag, http.StatusNotModified, false},
+		{"If-None-Match en una lista", "GET", "If-None-Match", `"otro", ` + etag, http.StatusNotModified, false},
+		{"If-None-Match distinto", "GET", "If-None-Match", `"otro"`, http.StatusOK, true},
+		{"If-Modified-Since igual", "GET", "If-Modified-Since", lastModified.Format(http.TimeFormat), http.StatusNotModified, false},
+		{"If-Modified-Since anterior", "GET", "If-Modified-Since", lastModified.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/v1/semesters", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			api.writeCatalog(rec, r, "semesters", semesters, "error")
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("ETag = %s, se esperaba %s", got, etag)
+			}
+			if got := rec.Header().Get("Cache-Control"); got != catalogCacheControl {
+				t.Errorf("Cache-Control = %q, se esperaba %q", got, catalogCacheControl)
+			}
+			if tt.wantBody {
+				if got := rec.Body.String(); got != first.Body.String() || got == "" {
+					t.Errorf("cuerpo = %q, se esperaba %q", got, first.Body)
+				}
+				if got := rec.Header().Get("Content-Type"); got != "application/json" {
+					t.Errorf("Content-Type = %q", got)
+				}
+			} else if rec.Body.Len() != 0 {
+				t.Errorf("se esperaba una respuesta sin cuerpo, llegó %q", rec.Body)
+			}
+		})
+	}
+}
+
+func TestWriteCatalogErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"no encontrado", pgx.ErrNoRows, http.StatusNotFound},
+		{"error de la base", errors.New("falla"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			rec := httptest.NewRecorder()
+			api.writeCatalog(rec, httptest.NewRequest("GET", "/v1/semesters", nil), "semesters",
+				func(context.Context) (any, error) { return nil, tt.err }, "Error al obtener semestres")
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Header().Get("ETag") != "" {
+				t.Error("una respuesta de error no debe llevar ETag")
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package api
+
+import (
+	"alumnos/models"
+	"alumnos/openapi"
+	"alumnos/openapi/openapitest"
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// contract indica los modelos que decodifica y codifica el handler de una ruta.
+type contract struct {
+	Request  any
+	Response any
+}
+
+// contracts da a openapitest.Check un valor de cada modelo. TestContractsMatchHandlers
+// verifica que coincidan con lo que los handlers realmente decodifican y
+// codifican, así que esta tabla no puede quedar desactualizada en silencio.
+// Las rutas sin cuerpo JSON (p. ej. los DELETE con 204) no llevan entrada.
+var contracts = map[string]contract{
+	"POST /v1/auth/login":                      {models.LoginRequest{}, models.LoginResponse{}},
+	"POST /v1/auth/alumnos/login":              {models.LoginRequest{}, models.LoginResponse{}},
+	"POST /v1/alumnos":                         {models.RegisterAlumnRequest{}, models.RegisterAlumnResponse{}},
+	"GET /v1/alumnos/search":                   {nil, []models.StudentSearchResult{}},
+	"GET /v1/alumnos/{id}":                     {nil, models.Alumno{}},
+	"PATCH /v1/alumnos/{id}":                   {models.UpdateAlumnRequest{}, models.Alumno{}},
+	"POST /v1/alumnos/{id}/restore":            {nil, models.MessageResponse{}},
+	"GET /v1/alumnos/{id}/calificaciones":      {nil, models.CalificacionesAgrupadasResponse{}},
+	"GET /v1/alumnos/{id}/pending-grades":      {nil, models.PendingGradesResponse{}},
+	"GET /v1/alumnos/{id}/semester-courses":    {nil, []models.SemesterCourse{}},
+	"GET /v1/alumnos/{id}/completed-semesters": {nil, []models.SemesterGrades{}},
+	"POST /v1/semestres":                       {models.EnrollSemesterRequest{}, models.MessageResponse{}},
+	"POST /v1/calificaciones/parcial":          {models.PartialGradeRequest{}, models.MessageResponse{}},
+	"GET /v1/courses":                          {nil, []models.Course{}},
+	"GET /v1/courses/{id}/subjects":            {nil, []models.Subject{}},
+	"POST /v1/courses/{id}/subjects":           {models.SubjectRequest{}, models.Subject{}},
+	"PATCH /v1/subjects/{id}":                  {models.SubjectRequest{}, models.Subject{}},
+	"POST /v1/subjects/{id}/retire":            {nil, models.MessageResponse{}},
+	"GET /v1/students":                         {nil, models.StudentPage{}},
+	"GET /v1/semesters":                        {nil, []models.CatSemester{}},
+	"GET /v1/periods":                          {nil, []models.AcademicPeriod{}},
+	"POST /v1/periods":                         {models.AcademicPeriodRequest{}, models.AcademicPeriod{}},
+	"POST /v1/teacher-subjects":                {models.TeacherSubjectRequest{}, models.MessageResponse{}},
+	"POST /v1/alumnos/pending-grades":          {models.AlumnIDRequest{}, models.PendingGradesResponse{}},
+	"POST /v1/calificaciones/agrupadas":        {models.CalificacionesAgrupadasRequest{}, models.CalificacionesAgrupadasResponse{}},
+	"POST /v1/courses/subjects":                {models.CourseIDRequest{}, []models.Subject{}},
+	"POST /v1/semester-courses":                {models.AlumnIDRequest{}, []models.SemesterCourse{}},
+	"POST /v1/completed-semesters":             {models.AlumnIDRequest{}, []models.SemesterGrades{}},
+	"GET /healthz":                             {nil, models.HealthResponse{}},
+	"GET /readyz":                              {nil, models.ReadinessResponse{}},
+	"GET /openapi.json":                        {nil, map[string]any{}},
+}
+
+// unanalyzed son las rutas cuyo handler no está en este paquete; su entrada
+// en contracts no se puede derivar del código.
+var unanalyzed = map[string]string{
+	"GET /openapi.json": "openapi.ServeSpec escribe el documento embebido",
+	"GET /docs":         "openapi.ServeDocs responde HTML",
+	"GET /metrics":      "formato de texto de Prometheus",
+}
+
+// La especificación debe documentar cada ruta registrada con sus modelos.
+func TestOpenAPISpec(t *testing.T) {
+	routes := Routes(NewAPI(nil, nil, Options{}))
+	operations := make([]openapitest.Operation, 0, len(routes))
+	for _, route := range routes {
+		c := contracts[route.Pattern]
+		operations = append(operations, openapitest.Operation{
+			Pattern:  route.Pattern,
+			Request:  c.Request,
+			Response: c.Response,
+		})
+	}
+
+	problems, err := openapitest.Check(openapi.Spec(), operations, map[string]any{
+		"ErrorResponse": ErrorResponse{},
+	})
+	if err != nil {
+		t.Fatalf("openapitest.Check: %v", err)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
+
+// Cada entrada de contracts debe coincidir con los tipos que el handler de la
+// ruta pasa a decodeJSON y a json.Encoder.Encode, según el código fuente.
+func TestContractsMatchHandlers(t *testing.T) {
+	derived := deriveContracts(t)
+
+	for _, route := range Routes(NewAPI(nil, nil, Options{})) {
+		t.Run(route.Pattern, func(t *testing.T) {
+			want := contracts[route.Pattern]
+			got, ok := derived[route.Pattern]
+			if _, skip := unanalyzed[route.Pattern]; skip {
+				if ok {
+					t.Errorf("el handler está en el paquete api; quitar la ruta de unanalyzed")
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("no se encontró el handler de la ruta en Routes")
+			}
+
+			if w := reflectTypeString(want.Request); got.Request != w {
+				t.Errorf("el handler decodifica %s pero contracts dice %s", orNone(got.Request), orNone(w))
+			}
+			if w := reflectTypeString(want.Response); got.Response != w {
+				t.Errorf("el handler codifica %s pero contracts dice %s", orNone(got.Response), orNone(w))
+			}
+		})
+	}
+
+	for pattern := range contracts {
+		if _, ok := derived[pattern]; !ok {
+			if _, skip := unanalyzed[pattern]; !skip {
+				t.Errorf("contracts tiene %s, que no está en Routes", pattern)
+			}
+		}
+	}
+}
+
+func orNone(typ string) string {
+	if typ == "" {
+		return "ningún cuerpo"
+	}
+	return typ
+}
+
+// derivedContract guarda los tipos como los escribe types.TypeString.
+type derivedContract struct {
+	Request  string
+	Response string
+}
+
+// deriveContracts analiza el código del paquete: busca en Routes el handler
+// de cada patrón y recorre su cuerpo, y el de las funciones del paquete que
+// llama, en busca de api.decodeJSON(w, r, &x), json.NewEncoder(w).Encode(x) y
+// writeCatalog(..., func(...) (any, error) { return x, ... }, ...).
+func deriveContracts(t *testing.T) map[string]derivedContract {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatalf("error al leer %s: %v", path, err)
+		}
+		files = append(files, file)
+	}
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", exportLookup(t))}
+	pkg, err := conf.Check("alumnos/api", fset, files, info)
+	if err != nil {
+		t.Fatalf("error al analizar el paquete api: %v", err)
+	}
+
+	a := analyzer{pkg: pkg, info: info, decls: make(map[*types.Func]*ast.FuncDecl)}
+	var routes *ast.FuncDecl
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				a.decls[info.Defs[fn.Name].(*types.Func)] = fn
+				if fn.Name.Name == "Routes" && fn.Recv == nil {
+					routes = fn
+				}
+			}
+		}
+	}
+
+	derived := make(map[string]derivedContract)
+	ast.Inspect(routes.Body, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || len(lit.Elts) != 2 {
+			return true
+		}
+		pattern, ok := lit.Elts[0].(*ast.BasicLit)
+		if !ok || pattern.Kind != token.STRING {
+			return true
+		}
+		key := strings.Trim(pattern.Value, `"`)
+
+		handlers := a.handlerFuncs(lit.Elts[1])
+		if len(handlers) != 1 {
+			if _, skip := unanalyzed[key]; !skip {
+				t.Errorf("%s: se esperaba un handler y se encontraron %d", key, len(handlers))
+			}
+			return false
+		}
+		if _, ok := a.decls[handlers[0]]; !ok {
+			return false // handler de otro paquete
+		}
+
+		found := a.collect(handlers[0], make(map[*types.Func]bool))
+		derived[key] = derivedContract{
+			Request:  single(t, key, "decodifica", found.requests),
+			Response: single(t, key, "codifica", found.responses),
+		}
+		return false
+	})
+	return derived
+}
+
+// exportLookup ubica los datos de exportación compilados de cada dependencia
+// con go list, como hace golang.org/x/tools/go/packages.
+func exportLookup(t *testing.T) func(path string) (io.ReadCloser, error) {
+	t.Helper()
+	out, err := exec.Command("go", "list", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}", ".").Output()
+	if err != nil {
+		t.Fatalf("go list -export: %v", err)
+	}
+	exports := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		path, export, _ := strings.Cut(scanner.Text(), "\t")
+		exports[path] = export
+	}
+	return func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok || export == "" {
+			return nil, fmt.Errorf("no hay datos de exportación de %s", path)
+		}
+		return os.Open(export)
+	}
+}
+
+type analyzer struct {
+	pkg   *types.Package
+	info  *types.Info
+	decls map[*types.Func]*ast.FuncDecl
+}
+
+type bodies struct {
+	requests  []string
+	responses []string
+}
+
+// handlerFuncs devuelve las funciones con firma de handler que aparecen como
+// valor (no llamadas) en la expresión de una ruta, p. ej. GetAlumno en
+// apiInstance.Protect(apiInstance.GetAlumno).
+func (a *analyzer) handlerFuncs(expr ast.Expr) []*types.Func {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		var funcs []*types.Func
+		for _, arg := range e.Args {
+			funcs = append(funcs, a.handlerFuncs(arg)...)
+		}
+		return funcs
+	case *ast.SelectorExpr:
+		return a.handlerFuncs(e.Sel)
+	case *ast.Ident:
+		if fn, ok := a.info.Uses[e].(*types.Func); ok && isHandler(fn) {
+			return []*types.Func{fn}
+		}
+	}
+	return nil
+}
+
+func isHandler(fn *types.Func) bool {
+	sig := fn.Type().(*types.Signature)
+	params := sig.Params()
+	return sig.Results().Len() == 0 && params.Len() == 2 &&
+		types.TypeString(params.At(0).Type(), nil) == "net/http.ResponseWriter" &&
+		types.TypeString(params.At(1).Type(), nil) == "*net/http.Request"
+}
+
+// collect reúne los tipos que fn y las funciones del paquete que llama pasan
+// a decodeJSON y a json.Encoder.Encode. ErrorResponse se omite: es el cuerpo
+// de los errores, que la especificación documenta aparte.
+func (a *analyzer) collect(fn *types.Func, visited map[*types.Func]bool) bodies {
+	var found bodies
+	if visited[fn] {
+		return found
+	}
+	visited[fn] = true
+
+	decl, ok := a.decls[fn]
+	if !ok || decl.Body == nil {
+		return found
+	}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		callee := a.callee(call)
+
+		switch {
+		case callee != nil && callee.Pkg() == a.pkg && callee.Name() == "decodeJSON":
+			if ptr, ok := a.info.TypeOf(call.Args[2]).(*types.Pointer); ok {
+				found.requests = appendType(found.requests, ptr.Elem())
+			}
+		case callee != nil && callee.Name() == "Encode" && isJSONEncoder(callee):
+			// Codificar *T produce el mismo JSON que T
+			typ := a.info.TypeOf(call.Args[0])
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+			found.responses = appendType(found.responses, typ)
+		case callee != nil && callee.Pkg() == a.pkg && callee.Name() == "writeCatalog":
+			found.responses = appendType(found.responses, a.catalogType(call.Args[3]))
+		case callee != nil && callee.Pkg() == a.pkg:
+			nested := a.collect(callee, visited)
+			for _, typ := range nested.requests {
+				found.requests = appendString(found.requests, typ)
+			}
+			for _, typ := range nested.responses {
+				found.responses = appendString(found.responses, typ)
+			}
+		}
+		return true
+	})
+
+	found.responses = slices.DeleteFunc(found.responses, func(typ string) bool {
+		return typ == "alumnos/api.ErrorResponse"
+	})
+	return found
+}
+
+// callee devuelve la función o el método que invoca call, o nil si es una
+// conversión o una llamada a una variable.
+func (a *analyzer) callee(call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := a.info.Uses[ident].(*types.Func)
+	return fn
+}
+
+func isJSONEncoder(fn *types.Func) bool {
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && types.TypeString(recv.Type(), nil) == "*encoding/json.Encoder"
+}
+
+// catalogType devuelve el tipo del primer valor que devuelve la función que
+// carga el catálogo, p. ej. []models.CatSemester en
+// func(ctx context.Context) (any, error) { return api.Repo.GetCatSemesters(ctx) }.
+func (a *analyzer) catalogType(load ast.Expr) types.Type {
+	lit, ok := load.(*ast.FuncLit)
+	if !ok {
+		return nil
+	}
+	var typ types.Type
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) == 0 || typ != nil {
+			return typ == nil
+		}
+		typ = a.info.TypeOf(ret.Results[0])
+		if tuple, ok := typ.(*types.Tuple); ok {
+			typ = tuple.At(0).Type()
+		}
+		return false
+	})
+	return typ
+}
+
+func appendType(list []string, typ types.Type) []string {
+	if typ == nil {
+		return list
+	}
+	return appendString(list, types.TypeString(typ, nil))
+}
+
+func appendString(list []string, typ string) []string {
+	if slices.Contains(list, typ) {
+		return list
+	}
+	return append(list, typ)
+}
+
+// single devuelve el único tipo encontrado, o "" si no hay ninguno.
+func single(t *testing.T, pattern, verb string, list []string) string {
+	t.Helper()
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	}
+	sort.Strings(list)
+	t.Errorf("%s: el handler %s varios tipos: %s", pattern, verb, strings.Join(list, ", "))
+	return list[0]
+}
+
+// reflectTypeString escribe un tipo de reflect con el formato de
+// types.TypeString, p. ej. "[]alumnos/models.Subject".
+func reflectTypeString(value any) string {
+	if value == nil {
+		return ""
+	}
+	return typeString(reflect.TypeOf(value))
+}
+
+func typeString(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name()
+		}
+		return t.PkgPath() + "." + t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return "[]" + typeString(t.Elem())
+	case reflect.Pointer:
+		return "*" + typeString(t.Elem())
+	case reflect.Map:
+		return "map[" + typeString(t.Key()) + "]" + typeString(t.Elem())
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	}
+	return t.String()
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"alumnos/logging"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy indica qué orígenes del navegador pueden llamar a la API.
+type CORSPolicy struct {
+	// AllowedOrigins acepta orígenes exactos (https://app.ax01.dev), subdominios
+	// con comodín (https://*.ax01.dev) o "*" para cualquiera sin credenciales.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // cuánto puede el navegador guardar un preflight
+}
+
+// exposedHeaders son los headers de respuesta que el frontend puede leer.
+var exposedHeaders = []string{"X-Request-ID", "Retry-After", "Traceparent", "Deprecation", "Sunset", "Link", "Idempotent-Replayed"}
+
+// CORS agrega los headers de CORS para los orígenes permitidos y responde los
+// preflight OPTIONS de cualquier ruta de mux con los métodos que esa ruta
+// acepta. Los orígenes no permitidos se registran y no reciben headers, así
+// que el navegador bloquea la respuesta. Va entre LogRequests y
+// LimitRequests para que las respuestas 429 también lleven los headers.
+// Sin orígenes configurados no hace nada.
+func CORS(policy CORSPolicy, mux *http.ServeMux, next http.Handler) http.Handler {
+	if len(policy.AllowedOrigins) == 0 {
+		return next
+	}
+
+	allowedHeaders := strings.Join(policy.AllowedHeaders, ", ")
+	exposed := strings.Join(exposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(policy.MaxAge / time.Second))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || sameOrigin(r, origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		w.Header().Add("Vary", "Origin")
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !policy.allowsOrigin(origin) {
+			logging.FromContext(r.Context(), nil).Warn("origen no permitido por CORS",
+				"origin", origin, "method", r.Method, "path", r.URL.Path)
+			if preflight {
+				writeError(w, r, http.StatusForbidden, CodeForbidden, "Origen no permitido")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if policy.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			w.Header().Set("Access-Control-Expose-Headers", exposed)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		methods, pattern := policy.routeMethods(mux, r)
+		if len(methods) == 0 {
+			writeError(w, r, http.StatusNotFound, CodeNotFound, "La ruta no existe")
+			return
+		}
+		// LogRequests y las métricas agrupan el preflight con su ruta
+		r.Pattern = http.MethodOptions + " " + pattern
+
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+		w.Header().Set("Access-Control-Max-Age", maxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowsOrigin compara el origen con la lista sin distinguir mayúsculas.
+func (p CORSPolicy) allowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		// https://*.ax01.dev permite https://app.ax01.dev pero no https://ax01.dev
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok &&
+			strings.HasPrefix(origin, scheme+"://") && strings.HasSuffix(origin, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeMethods devuelve los métodos permitidos que la ruta de r acepta,
+// consultando al mux con cada uno, y el patrón de la ruta sin el método.
+func (p CORSPolicy) routeMethods(mux *http.ServeMux, r *http.Request) ([]string, string) {
+	var methods []string
+	var route string
+	for _, method := range p.AllowedMethods {
+		probe := &http.Request{Method: method, URL: r.URL, Host: r.Host, Header: http.Header{}}
+		if _, pattern := mux.Handler(probe); pattern != "" {
+			methods = append(methods, method)
+			if _, path, ok := strings.Cut(pattern, " "); ok {
+				pattern = path
+			}
+			route = pattern
+		}
+	}
+	return methods, route
+}
+
+// sameOrigin detecta las solicitudes del propio host (los navegadores envían
+// Origin también en los POST del mismo origen), que no necesitan CORS.
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
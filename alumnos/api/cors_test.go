@@ -0,0 +1,118 @@
+package api
+
+import (
+	"alumnos/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"https://app.ax01.dev", "https://*.alumnos.mx"},
+		AllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+
+	tests := []struct {
+		name          string
+		policy        CORSPolicy
+		method        string
+		path          string
+		origin        string
+		requestMethod string // Access-Control-Request-Method; vacío si no es preflight
+		wantStatus    int
+		wantOrigin    string // Access-Control-Allow-Origin
+		wantMethods   string // Access-Control-Allow-Methods
+	}{
+		{"sin Origin", policy, "GET", "/healthz", "", "", http.StatusOK, "", ""},
+		{"mismo origen", policy, "GET", "/healthz", "http://example.com", "", http.StatusOK, "", ""},
+		{"origen exacto", policy, "GET", "/healthz", "https://app.ax01.dev", "", http.StatusOK, "https://app.ax01.dev", ""},
+		{"sin distinguir mayúsculas", policy, "GET", "/healthz", "https://APP.ax01.dev", "", http.StatusOK, "https://APP.ax01.dev", ""},
+		{"subdominio con comodín", policy, "GET", "/healthz", "https://admin.alumnos.mx", "", http.StatusOK, "https://admin.alumnos.mx", ""},
+		{"el comodín no incluye el dominio", policy, "GET", "/healthz", "https://alumnos.mx", "", http.StatusOK, "", ""},
+		{"el comodín respeta el esquema", policy, "GET", "/healthz", "http://admin.alumnos.mx", "", http.StatusOK, "", ""},
+		{"origen no permitido", policy, "GET", "/healthz", "https://evil.dev", "", http.StatusOK, "", ""},
+		{"preflight", policy, "OPTIONS", "/v1/alumnos/1", "https://app.ax01.dev", "PATCH", http.StatusNoContent, "https://app.ax01.dev", "GET, PATCH, DELETE"},
+		{"preflight de origen no permitido", policy, "OPTIONS", "/v1/alumnos/1", "https://evil.dev", "PATCH", http.StatusForbidden, "", ""},
+		{"preflight de ruta inexistente", policy, "OPTIONS", "/v1/nada", "https://app.ax01.dev", "GET", http.StatusNotFound, "https://app.ax01.dev", ""},
+		{"cualquier origen", CORSPolicy{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}, "GET", "/healthz", "https://evil.dev", "", http.StatusOK, "https://evil.dev", ""},
+		{"desactivado", CORSPolicy{}, "GET", "/healthz", "https://app.ax01.dev", "", http.StatusOK, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := newTestMux(newTestAPI(t))
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.requestMethod != "" {
+				r.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			rec := httptest.NewRecorder()
+			CORS(tt.policy, mux, mux).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			header := rec.Header()
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, se esperaba %q", got, tt.wantOrigin)
+			}
+			if got := header.Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, se esperaba %q", got, tt.wantMethods)
+			}
+			if tt.wantOrigin == "" {
+				return
+			}
+
+			if got := header.Get("Vary"); got != "Origin" && tt.requestMethod == "" {
+				t.Errorf("Vary = %q, se esperaba %q", got, "Origin")
+			}
+			wantCredentials := ""
+			if tt.policy.AllowCredentials {
+				wantCredentials = "true"
+			}
+			if got := header.Get("Access-Control-Allow-Credentials"); got != wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, se esperaba %q", got, wantCredentials)
+			}
+			if tt.wantStatus == http.StatusNoContent {
+				if got := header.Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type" {
+					t.Errorf("Access-Control-Allow-Headers = %q", got)
+				}
+				if got := header.Get("Access-Control-Max-Age"); got != "600" {
+					t.Errorf("Access-Control-Max-Age = %q, se esperaba %q", got, "600")
+				}
+			} else if tt.requestMethod == "" && header.Get("Access-Control-Expose-Headers") == "" {
+				t.Error("falta Access-Control-Expose-Headers")
+			}
+		})
+	}
+}
+
+// Las respuestas 429 de LimitRequests también llevan los headers de CORS para
+// que el frontend pueda leer Retry-After.
+func TestCORSRateLimited(t *testing.T) {
+	mux := newTestMux(newTestAPI(t))
+	policy := CORSPolicy{AllowedOrigins: []string{"https://app.ax01.dev"}, AllowedMethods: []string{"GET"}}
+	handler := CORS(policy, mux, LimitRequests(ratelimit.New(0.001, 1), nil, 1<<20, mux))
+
+	var rec *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest("GET", "/v1/courses/x/subjects", nil)
+		r.Header.Set("Origin", "https://app.ax01.dev")
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+	}
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, se esperaba %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.ax01.dev" {
+		t.Errorf("Access-Control-Allow-Origin = %q en la respuesta 429", got)
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"alumnos/logging"
+	"alumnos/tracing"
+	"alumnos/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Códigos estables para que el frontend distinga los errores sin leer el mensaje.
+const (
+	CodeBadRequest    = "bad_request"
+	CodeInvalidJSON   = "invalid_json"
+	CodeValidation    = "validation_failed"
+	CodeTooLarge      = "payload_too_large"
+	CodeUnauthorized  = "unauthorized"
+	CodeForbidden     = "forbidden"
+	CodeNotFound      = "not_found"
+	CodeConflict      = "conflict"
+	CodeUnprocessable = "unprocessable_entity"
+	CodeInternal      = "internal_error"
+	CodeUnavailable   = "service_unavailable"
+	CodeRateLimited   = "too_many_requests"
+
+	CodeIdempotencyMismatch   = "idempotency_key_mismatch"
+	CodeIdempotencyInProgress = "idempotency_key_in_progress"
+)
+
+// Códigos SQLSTATE de PostgreSQL que se traducen a errores del cliente.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+)
+
+// ErrorResponse es el cuerpo de todas las respuestas de error de la API.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	TraceID   string       `json:"trace_id,omitempty"`
+}
+
+// FieldError describe un problema con un campo concreto de la solicitud.
+type FieldError = validation.FieldError
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string, fields ...FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error: ErrorBody{
+			Code:      code,
+			Message:   message,
+			Fields:    fields,
+			RequestID: RequestIDFromContext(r.Context()),
+			TraceID:   tracing.TraceID(r.Context()),
+		},
+	})
+}
+
+// writeFieldError responde 400 con un único campo inválido.
+func writeFieldError(w http.ResponseWriter, r *http.Request, field, message string) {
+	writeError(w, r, http.StatusBadRequest, CodeBadRequest, message, FieldError{Field: field, Message: message})
+}
+
+// writeValidationError responde 400 con todos los campos inválidos a la vez.
+func writeValidationError(w http.ResponseWriter, r *http.Request, fields []FieldError) {
+	writeError(w, r, http.StatusBadRequest, CodeValidation, "La solicitud tiene campos inválidos", fields...)
+}
+
+// writeRateLimited responde 429 con el tiempo que el cliente debe esperar.
+func writeRateLimited(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "Demasiadas solicitudes; intente de nuevo más tarde")
+}
+
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		writeError(w, r, http.StatusRequestEntityTooLarge, CodeTooLarge,
+			fmt.Sprintf("El cuerpo de la solicitud excede %d bytes", maxBytesErr.Limit))
+	case errors.As(err, &typeErr):
+		writeError(w, r, http.StatusBadRequest, CodeInvalidJSON, "Error al decodificar la solicitud",
+			FieldError{Field: typeErr.Field, Message: fmt.Sprintf("debe ser de tipo %s", typeErr.Type)})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json no exporta un tipo para este error
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeError(w, r, http.StatusBadRequest, CodeInvalidJSON, "Error al decodificar la solicitud",
+			FieldError{Field: field, Message: "campo desconocido"})
+	default:
+		writeError(w, r, http.StatusBadRequest, CodeInvalidJSON, fmt.Sprintf("Error al decodificar la solicitud: %v", err))
+	}
+}
+
+// writeRepoError traduce los errores del repositorio a una respuesta. Los
+// errores no reconocidos se registran en el log con el ID de la solicitud y
+// al cliente solo se le envía message, sin detalles de pgx ni de SQL.
+func writeRepoError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Registro no encontrado")
+		return
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			writeError(w, r, http.StatusConflict, CodeConflict, "Ya existe un registro con esos datos")
+			return
+		case pgForeignKeyViolation:
+			writeError(w, r, http.StatusUnprocessableEntity, CodeUnprocessable, "La solicitud hace referencia a un registro que no existe")
+			return
+		case pgCheckViolation:
+			writeError(w, r, http.StatusUnprocessableEntity, CodeUnprocessable, "Los datos no cumplen las reglas del registro")
+			return
+		}
+	}
+
+	// Base de datos caída: el servicio sigue en modo degradado y el cliente puede reintentar
+	if isUnavailable(err) {
+		logging.FromContext(r.Context(), nil).Warn(message, "error", err)
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+		writeError(w, r, http.StatusServiceUnavailable, CodeUnavailable, "El servicio no está disponible temporalmente; intente de nuevo más tarde")
+		return
+	}
+
+	logging.FromContext(r.Context(), nil).Error(message, "error", err)
+	writeError(w, r, http.StatusInternalServerError, CodeInternal, message)
+}
+
+// retryAfterSeconds es la espera sugerida cuando la base de datos no responde.
+const retryAfterSeconds = 5
+
+// isUnavailable indica si err proviene de no poder hablar con la base de
+// datos (conexión rechazada, cortada o sin respuesta), no de la consulta.
+func isUnavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		pgconn.Timeout(err)
+}
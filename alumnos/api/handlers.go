@@ -1,106 +1,263 @@
 package api
 
 import (
+	"alumnos/auth"
+	"alumnos/catalog"
+	"alumnos/logging"
+	"alumnos/metrics"
 	"alumnos/models"
+	"alumnos/ratelimit"
 	"alumnos/repository"
-	"bytes"
+	"alumnos/validation"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type API struct {
-	Repo *repository.PgxStorage
+	Repo    *repository.PgxStorage
+	Auth    *auth.Manager
+	Metrics *metrics.Metrics // puede ser nil
+
+	// UserLimiter limita las solicitudes de cada usuario autenticado; nil no limita
+	UserLimiter *ratelimit.Limiter
+	// Catalog guarda carreras, materias y semestres; nil consulta siempre la base
+	Catalog *catalog.Cache
+	// MaxBodyBytes limita los cuerpos que leen decodeJSON e Idempotent; 0 usa DefaultMaxBodyBytes
+	MaxBodyBytes int64
 }
 
-func NewAPI(repo *repository.PgxStorage) *API {
-	return &API{Repo: repo}
+// DefaultMaxBodyBytes es el límite de cuerpo cuando no se configura otro; es
+// el mismo valor por defecto de http-max-body-bytes.
+const DefaultMaxBodyBytes = 1 << 20
+
+// Options son las dependencias y los límites opcionales de NewAPI. El valor
+// cero de cada campo es válido.
+type Options struct {
+	Metrics      *metrics.Metrics
+	UserLimiter  *ratelimit.Limiter
+	Catalog      *catalog.Cache
+	MaxBodyBytes int64 // 0 o negativo usa DefaultMaxBodyBytes
 }
 
-func (api *API) RegistrarAlumno(w http.ResponseWriter, r *http.Request) {
-	// Leer y registrar el cuerpo recibido
-	body, _ := io.ReadAll(r.Body)
-	fmt.Println("Cuerpo recibido:", string(body))
+func NewAPI(repo *repository.PgxStorage, authManager *auth.Manager, opts Options) *API {
+	return &API{
+		Repo:         repo,
+		Auth:         authManager,
+		Metrics:      opts.Metrics,
+		UserLimiter:  opts.UserLimiter,
+		Catalog:      opts.Catalog,
+		MaxBodyBytes: bodyLimit(opts.MaxBodyBytes),
+	}
+}
+
+// bodyLimit devuelve max, o DefaultMaxBodyBytes si no es positivo: un límite
+// de 0 rechazaría cualquier cuerpo.
+func bodyLimit(max int64) int64 {
+	if max <= 0 {
+		return DefaultMaxBodyBytes
+	}
+	return max
+}
 
+func (api *API) RegistrarAlumno(w http.ResponseWriter, r *http.Request) {
 	// Decodificar el JSON
 	var request models.RegisterAlumnRequest
-	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&request); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	if !api.decodeJSON(w, r, &request) {
 		return
 	}
-	// Log del contenido decodificado
-	fmt.Printf("Solicitud decodificada: %+v\n", request)
+	// Solo datos no personales; los nombres y el correo no se registran
+	logging.FromContext(r.Context(), nil).Debug("registro de alumno recibido",
+		"course_id", request.CourseID,
+		"current_course_id", request.CurrentCourseID,
+		"subjects", len(request.Subjects),
+		"period_code", request.PeriodCode)
 
-	// Validar campos requeridos
-	if request.Name == "" || request.Lastname1 == "" || request.CourseID == 0 || request.CurrentCourseID == 0 {
-		http.Error(w, "Faltan campos requeridos (name, lastname1, course_id, current_course_id)", http.StatusBadRequest)
+	// Validar todos los campos antes de responder
+	v := validation.New()
+	v.Required("name", request.Name)
+	v.Required("lastname1", request.Lastname1)
+	v.PositiveID("course_id", request.CourseID)
+	v.PositiveID("current_course_id", request.CurrentCourseID)
+
+	subjectIDs := make([]int, len(request.Subjects))
+	for i, subject := range request.Subjects {
+		subjectIDs[i] = subject.ID
+	}
+	v.UniqueIDs("subjects", subjectIDs)
+
+	// Las credenciales del alumno son opcionales, pero van juntas
+	if request.Email != "" {
+		v.Required("password", request.Password)
+	}
+
+	if err := v.Course(r.Context(), api.Repo, "course_id", request.CourseID); err != nil {
+		writeRepoError(w, r, err, "Error al registrar alumno")
 		return
 	}
+	if err := v.Semester(r.Context(), api.Repo, "current_course_id", request.CurrentCourseID); err != nil {
+		writeRepoError(w, r, err, "Error al registrar alumno")
+		return
+	}
+	if !v.HasField("course_id") {
+		if err := v.SubjectsInCourse(r.Context(), api.Repo, "subjects", request.CourseID, subjectIDs); err != nil {
+			writeRepoError(w, r, err, "Error al registrar alumno")
+			return
+		}
+	}
+
+	if !v.Valid() {
+		writeValidationError(w, r, v.Errors())
+		return
+	}
+
+	if request.Email != "" {
+		hash, err := auth.HashPassword(request.Password)
+		if err != nil {
+			writeRepoError(w, r, err, "Error al registrar alumno")
+			return
+		}
+		request.Password = hash
+	}
 
 	// Registrar alumno
 	alumnoID, err := api.Repo.RegisterAlumn(r.Context(), request)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, fmt.Sprintf("El periodo '%s' no existe", request.PeriodCode))
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al registrar alumno: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al registrar alumno")
 		return
 	}
 
+	api.Metrics.StudentRegistered()
+	api.Metrics.EnrollmentsCreated(len(request.Subjects))
+
 	// Responder con éxito
 	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"message":   "Alumno registrado exitosamente",
-		"alumno_id": alumnoID,
+	if err := json.NewEncoder(w).Encode(models.RegisterAlumnResponse{
+		Message:  "Alumno registrado exitosamente",
+		AlumnoID: alumnoID,
 	}); err != nil {
-		http.Error(w, fmt.Sprintf("Error al codificar la respuesta: %v", err), http.StatusInternalServerError)
+		// El estado ya se envió; solo queda registrar el fallo
+		logging.FromContext(r.Context(), nil).Error("error al codificar la respuesta", "error", err)
 	}
 }
 
 func (api *API) RegistrarEnSemestre(w http.ResponseWriter, r *http.Request) {
-	var input struct {
-		AlumnoID   int   `json:"alumno_id"`
-		SemesterID int   `json:"semester_id"`
-		SubjectIDs []int `json:"subject_ids"`
+	var input models.EnrollSemesterRequest
+
+	if !api.decodeJSON(w, r, &input) {
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	// Validar todos los campos antes de responder
+	v := validation.New()
+	v.PositiveID("alumno_id", input.AlumnoID)
+	v.PositiveID("semester_id", input.SemesterID)
+	if v.Check(len(input.SubjectIDs) > 0, "subject_ids", "debe incluir al menos una materia") {
+		v.UniqueIDs("subject_ids", input.SubjectIDs)
+	}
+
+	if err := v.Semester(r.Context(), api.Repo, "semester_id", input.SemesterID); err != nil {
+		writeRepoError(w, r, err, "Error al registrar en semestre")
 		return
 	}
 
-	// Validar campos requeridos
-	if input.AlumnoID == 0 || input.SemesterID == 0 || len(input.SubjectIDs) == 0 {
-		http.Error(w, "Alumno ID, Semester ID y Subject IDs son obligatorios", http.StatusBadRequest)
+	// Las materias deben ser de la carrera del alumno
+	if !v.HasField("alumno_id") {
+		courseID, err := api.Repo.GetAlumnCourseID(r.Context(), input.AlumnoID)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			v.Add("alumno_id", fmt.Sprintf("el alumno %d no existe", input.AlumnoID))
+		case err != nil:
+			writeRepoError(w, r, err, "Error al registrar en semestre")
+			return
+		default:
+			if err := v.SubjectsInCourse(r.Context(), api.Repo, "subject_ids", courseID, input.SubjectIDs); err != nil {
+				writeRepoError(w, r, err, "Error al registrar en semestre")
+				return
+			}
+		}
+	}
+
+	if !v.Valid() {
+		writeValidationError(w, r, v.Errors())
 		return
 	}
 
 	// Llama al método del repositorio
-	err := api.Repo.RegistrarEnSemestreConMaterias(r.Context(), input.AlumnoID, input.SemesterID, input.SubjectIDs)
+	err := api.Repo.RegistrarEnSemestreConMaterias(r.Context(), input.AlumnoID, input.SemesterID, input.SubjectIDs, input.PeriodCode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, fmt.Sprintf("El periodo '%s' no existe", input.PeriodCode))
+		return
+	}
+	var duplicate *repository.DuplicateEnrollmentError
+	if errors.As(err, &duplicate) {
+		writeDuplicateEnrollment(w, r, duplicate, input.SubjectIDs)
+		return
+	}
+	if errors.Is(err, repository.ErrIncompleteSemesters) {
+		writeError(w, r, http.StatusConflict, CodeConflict,
+			fmt.Sprintf("El alumno no puede inscribirse en el semestre %d porque tiene semestres anteriores sin calificación final", input.SemesterID))
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al registrar en semestre: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al registrar en semestre")
 		return
 	}
+	api.Metrics.EnrollmentsCreated(len(input.SubjectIDs))
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Alumno registrado en el semestre exitosamente",
+	json.NewEncoder(w).Encode(models.MessageResponse{
+		Message: "Alumno registrado en el semestre exitosamente",
 	})
 }
 
+// writeDuplicateEnrollment responde 409 nombrando las materias que el alumno
+// ya cursa en el semestre, con un campo por cada una de la solicitud.
+func writeDuplicateEnrollment(w http.ResponseWriter, r *http.Request, duplicate *repository.DuplicateEnrollmentError, subjectIDs []int) {
+	names := make([]string, len(duplicate.Subjects))
+	var fields []FieldError
+	for i, subject := range duplicate.Subjects {
+		names[i] = fmt.Sprintf("%s (%s)", subject.Name, subject.Key)
+		if index := slices.Index(subjectIDs, subject.ID); index >= 0 {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("subject_ids[%d]", index),
+				Message: fmt.Sprintf("el alumno ya está inscrito en %s en este semestre", names[i]),
+			})
+		}
+	}
+
+	message := "El alumno ya está inscrito en alguna de las materias en este semestre"
+	if len(names) > 0 {
+		message = fmt.Sprintf("El alumno ya está inscrito en el semestre %d en: %s", duplicate.SemesterID, strings.Join(names, ", "))
+	}
+	writeError(w, r, http.StatusConflict, CodeConflict, message, fields...)
+}
+
 func (api *API) RegistrarCalificacionParcial(w http.ResponseWriter, r *http.Request) {
-	var input struct {
-		SemesterCourseID int     `json:"semester_course_id"`
-		PartialNumber    int     `json:"partial_number"`
-		Grade            float64 `json:"grade"`
-	}
+	var input models.PartialGradeRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	if !api.decodeJSON(w, r, &input) {
 		return
 	}
 
-	// Validar campos requeridos
-	if input.SemesterCourseID == 0 || input.PartialNumber == 0 || input.Grade < 0 {
-		http.Error(w, "SemesterCourseID, PartialNumber y Grade son obligatorios y deben ser válidos", http.StatusBadRequest)
+	// Validar todos los campos antes de responder
+	v := validation.New()
+	v.PositiveID("semester_course_id", input.SemesterCourseID)
+	v.PartialNumber("partial_number", input.PartialNumber)
+	v.Grade("grade", input.Grade)
+	if !v.Valid() {
+		writeValidationError(w, r, v.Errors())
 		return
 	}
 
@@ -108,167 +265,352 @@ func (api *API) RegistrarCalificacionParcial(w http.ResponseWriter, r *http.Requ
 	var alumnID int
 	err := api.Repo.GetAlumnIDBySemesterCourseID(r.Context(), input.SemesterCourseID, &alumnID)
 	if err != nil {
-		http.Error(w, "El ID de curso-semestre no pertenece a un alumno válido", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "El ID de curso-semestre no pertenece a un alumno válido")
 		return
 	}
 
+	// Un profesor solo califica las materias que imparte
+	claims, _ := auth.ClaimsFromContext(r.Context())
+	if claims.Role == auth.RoleTeacher {
+		teaches, err := api.Repo.TeacherTeachesSemesterCourse(r.Context(), claims.Subject, input.SemesterCourseID)
+		if err != nil {
+			writeRepoError(w, r, err, "Error al verificar permisos")
+			return
+		}
+		if !teaches {
+			writeError(w, r, http.StatusForbidden, CodeForbidden, "Acceso denegado: el profesor no imparte esta materia")
+			return
+		}
+	}
+
 	// Registrar calificación parcial
-	err = api.Repo.RegistrarCalificacionParcial(r.Context(), input.SemesterCourseID, input.PartialNumber, input.Grade)
+	err = api.Repo.RegistrarCalificacionParcial(r.Context(), input.SemesterCourseID, input.PartialNumber, *input.Grade)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// El alumno se eliminó después de la verificación
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "El ID de curso-semestre no pertenece a un alumno válido")
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al registrar calificación parcial: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al registrar calificación parcial")
 		return
 	}
+	api.Metrics.GradeRegistered(input.PartialNumber)
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Calificación parcial registrada exitosamente",
+	json.NewEncoder(w).Encode(models.MessageResponse{
+		Message: "Calificación parcial registrada exitosamente",
 	})
 }
 
 func (api *API) GenerarCalificacionesAgrupadas(w http.ResponseWriter, r *http.Request) {
 	// Estructura para decodificar el cuerpo de la solicitud
-	var input struct {
-		AlumnoID int `json:"alumno_id"`
-	}
+	var input models.CalificacionesAgrupadasRequest
 
 	// Decodificar el cuerpo JSON
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	if !api.decodeJSON(w, r, &input) {
 		return
 	}
 
 	// Validar que el ID del alumno sea válido
 	if input.AlumnoID == 0 {
-		http.Error(w, "El parámetro 'alumno_id' es obligatorio y debe ser válido", http.StatusBadRequest)
+		writeFieldError(w, r, "alumno_id", "El parámetro 'alumno_id' es obligatorio y debe ser válido")
+		return
+	}
+
+	api.writeCalificacionesAgrupadas(w, r, input.AlumnoID)
+}
+
+func (api *API) writeCalificacionesAgrupadas(w http.ResponseWriter, r *http.Request, alumnID int) {
+	if !authorizeAlumn(w, r, alumnID) {
 		return
 	}
 
 	// Llama al método del repositorio
-	semestres, promedioFinal, err := api.Repo.GenerarCalificacionesAgrupadasPorSemestre(r.Context(), input.AlumnoID)
+	semestres, promedioFinal, err := api.Repo.GenerarCalificacionesAgrupadasPorSemestre(r.Context(), alumnID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Alumno no encontrado")
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al generar calificaciones: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al generar calificaciones")
 		return
 	}
 
 	// Respuesta JSON
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"promedio_final": promedioFinal,
-		"semestres":      semestres,
+	json.NewEncoder(w).Encode(models.CalificacionesAgrupadasResponse{
+		PromedioFinal: promedioFinal,
+		Semestres:     semestres,
 	})
 }
 
 func (api *API) GetCourses(w http.ResponseWriter, r *http.Request) {
-	courses, err := api.Repo.GetCourses(r.Context())
+	api.writeCatalog(w, r, "courses", func(ctx context.Context) (any, error) {
+		return api.Repo.GetCourses(ctx)
+	}, "Error al obtener cursos")
+}
+
+func (api *API) GetSubjectsByCourse(w http.ResponseWriter, r *http.Request) {
+	// Decodificar el cuerpo de la solicitud
+	var input models.CourseIDRequest
+
+	if !api.decodeJSON(w, r, &input) {
+		return
+	}
+
+	// Validar que el CourseID sea válido
+	if input.CourseID <= 0 {
+		writeFieldError(w, r, "course_id", "El campo 'course_id' debe ser un número positivo")
+		return
+	}
+
+	api.writeSubjectsByCourse(w, r, input.CourseID)
+}
+
+func (api *API) writeSubjectsByCourse(w http.ResponseWriter, r *http.Request, courseID int) {
+	// Materias por CourseID, desde la caché de catálogos
+	api.writeCatalog(w, r, "subjects:"+strconv.Itoa(courseID), func(ctx context.Context) (any, error) {
+		return api.Repo.GetSubjectsByCourse(ctx, courseID)
+	}, "Error al obtener materias")
+}
+
+func (api *API) GetStudents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStudentFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+
+	// Obtener alumnos desde el repositorio
+	alumnos, total, err := api.Repo.GetStudents(r.Context(), filter)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al obtener cursos: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al obtener alumnos")
 		return
 	}
 
+	page := models.StudentPage{
+		Data:   alumnos,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+
+	// Enlace a la siguiente página conservando los filtros de la solicitud
+	if nextOffset := filter.Offset + filter.Limit; nextOffset < total {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(filter.Limit))
+		query.Set("offset", strconv.Itoa(nextOffset))
+		page.Next = r.URL.Path + "?" + query.Encode()
+	}
+
+	// Responder con JSON
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(courses)
+	json.NewEncoder(w).Encode(page)
 }
 
-func (api *API) GetSubjectsByCourse(w http.ResponseWriter, r *http.Request) {
-	// Decodificar el cuerpo de la solicitud
-	var input struct {
-		CourseID int `json:"course_id"`
+func (api *API) GetAlumno(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok || !authorizeAlumn(w, r, alumnID) {
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	alumno, err := api.Repo.GetStudentByID(r.Context(), alumnID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Alumno no encontrado")
+		return
+	}
+	if err != nil {
+		writeRepoError(w, r, err, "Error al obtener alumno")
 		return
 	}
 
-	// Validar que el CourseID sea válido
-	if input.CourseID <= 0 {
-		http.Error(w, "El campo 'course_id' debe ser un número positivo", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(alumno)
+}
+
+func (api *API) ActualizarAlumno(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.UpdateAlumnRequest
+	if !api.decodeJSON(w, r, &request) {
+		return
+	}
+
+	// Los campos obligatorios no pueden quedar vacíos
+	if (request.Name != nil && *request.Name == "") || (request.Lastname1 != nil && *request.Lastname1 == "") {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Los campos 'name' y 'lastname1' no pueden quedar vacíos")
+		return
+	}
+	if request.CourseID != nil && *request.CourseID <= 0 {
+		writeFieldError(w, r, "course_id", "El campo 'course_id' debe ser un número positivo")
 		return
 	}
 
-	// Obtener materias por CourseID desde el repositorio
-	subjects, err := api.Repo.GetSubjectsByCourse(r.Context(), input.CourseID)
+	alumno, err := api.Repo.UpdateStudent(r.Context(), alumnID, request)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Alumno no encontrado")
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al obtener materias: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al actualizar alumno")
 		return
 	}
 
-	// Responder con JSON
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(subjects)
+	json.NewEncoder(w).Encode(alumno)
 }
 
-func (api *API) GetStudents(w http.ResponseWriter, r *http.Request) {
-	// Obtener alumnos desde el repositorio
-	alumnos, err := api.Repo.GetStudents(r.Context())
+func (api *API) EliminarAlumno(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	err := api.Repo.SoftDeleteStudent(r.Context(), alumnID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Alumno no encontrado")
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al obtener alumnos: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al eliminar alumno")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *API) RestaurarAlumno(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	err := api.Repo.RestoreStudent(r.Context(), alumnID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "No existe un alumno eliminado con ese ID")
+		return
+	}
+	if err != nil {
+		writeRepoError(w, r, err, "Error al restaurar alumno")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.MessageResponse{
+		Message: "Alumno restaurado exitosamente",
+	})
+}
+
+func (api *API) SearchStudents(w http.ResponseWriter, r *http.Request) {
+	// Espacios repetidos no cambian la búsqueda
+	terms := strings.Fields(r.URL.Query().Get("q"))
+	if len(terms) == 0 {
+		writeFieldError(w, r, "q", "El parámetro 'q' es obligatorio")
+		return
+	}
+
+	limit, err := queryInt(r.URL.Query(), "limit", 20)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, err.Error())
+		return
+	}
+	if limit < 1 || limit > maxPageLimit {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, fmt.Sprintf("El parámetro 'limit' debe estar entre 1 y %d", maxPageLimit))
+		return
+	}
+
+	results, err := api.Repo.SearchStudents(r.Context(), strings.Join(terms, " "), limit)
+	if err != nil {
+		writeRepoError(w, r, err, "Error al buscar alumnos")
 		return
 	}
 
 	// Responder con JSON
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(alumnos)
+	json.NewEncoder(w).Encode(results)
 }
 
 func (api *API) GetPendingGradesHandler(w http.ResponseWriter, r *http.Request) {
 	// Decodificar el cuerpo de la solicitud
-	var input struct {
-		AlumnID int `json:"alumn_id"`
-	}
+	var input models.AlumnIDRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	if !api.decodeJSON(w, r, &input) {
 		return
 	}
 
 	// Validar que el AlumnID sea válido
 	if input.AlumnID <= 0 {
-		http.Error(w, "El campo 'alumn_id' debe ser un número positivo", http.StatusBadRequest)
+		writeFieldError(w, r, "alumn_id", "El campo 'alumn_id' debe ser un número positivo")
+		return
+	}
+
+	api.writePendingGrades(w, r, input.AlumnID)
+}
+
+func (api *API) writePendingGrades(w http.ResponseWriter, r *http.Request, alumnID int) {
+	if !authorizeAlumn(w, r, alumnID) {
 		return
 	}
 
 	// Obtener calificaciones pendientes desde el repositorio
-	pendingGrades, err := api.Repo.GetPendingGradesForCurrentSemester(r.Context(), input.AlumnID)
+	pendingGrades, err := api.Repo.GetPendingGradesForCurrentSemester(r.Context(), alumnID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Alumno no encontrado")
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al obtener calificaciones pendientes: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al obtener calificaciones pendientes")
 		return
 	}
 
 	// Responder con JSON
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message":        "Calificaciones pendientes obtenidas exitosamente",
-		"pending_grades": pendingGrades,
+	json.NewEncoder(w).Encode(models.PendingGradesResponse{
+		Message:       "Calificaciones pendientes obtenidas exitosamente",
+		PendingGrades: pendingGrades,
 	})
 }
 
 func (api *API) GetSemesterCoursesByAlumnId(w http.ResponseWriter, r *http.Request) {
 	// Decodificar el cuerpo de la solicitud
-	var input struct {
-		AlumnID int `json:"alumn_id"`
-	}
+	var input models.AlumnIDRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	if !api.decodeJSON(w, r, &input) {
 		return
 	}
 
 	// Validar que el AlumnID sea válido
 	if input.AlumnID <= 0 {
-		http.Error(w, "El campo 'alumn_id' debe ser un número positivo", http.StatusBadRequest)
+		writeFieldError(w, r, "alumn_id", "El campo 'alumn_id' debe ser un número positivo")
+		return
+	}
+
+	api.writeSemesterCourses(w, r, input.AlumnID)
+}
+
+func (api *API) writeSemesterCourses(w http.ResponseWriter, r *http.Request, alumnID int) {
+	if !authorizeAlumn(w, r, alumnID) {
 		return
 	}
 
 	// Obtener courses por AlumnID desde el repositorio
-	courses, err := api.Repo.GetSemesterCoursesByAlumnId(r.Context(), input.AlumnID)
+	courses, err := api.Repo.GetSemesterCoursesByAlumnId(r.Context(), alumnID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Alumno no encontrado")
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al obtener courses: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al obtener courses")
 		return
 	}
 
@@ -279,38 +621,41 @@ func (api *API) GetSemesterCoursesByAlumnId(w http.ResponseWriter, r *http.Reque
 }
 
 func (api *API) GetCatSemesters(w http.ResponseWriter, r *http.Request) {
-	semesters, err := api.Repo.GetCatSemesters(r.Context())
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al obtener semestres: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(semesters)
+	api.writeCatalog(w, r, "semesters", func(ctx context.Context) (any, error) {
+		return api.Repo.GetCatSemesters(ctx)
+	}, "Error al obtener semestres")
 }
 
 func (api *API) GetCompletedSemesters(w http.ResponseWriter, r *http.Request) {
 	// Decodificar el cuerpo de la solicitud
-	var input struct {
-		AlumnID int `json:"alumn_id"`
-	}
+	var input models.AlumnIDRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, fmt.Sprintf("Error al decodificar la solicitud: %v", err), http.StatusBadRequest)
+	if !api.decodeJSON(w, r, &input) {
 		return
 	}
 
 	// Validar que el AlumnID sea válido
 	if input.AlumnID <= 0 {
-		http.Error(w, "El campo 'alumn_id' debe ser un número positivo", http.StatusBadRequest)
+		writeFieldError(w, r, "alumn_id", "El campo 'alumn_id' debe ser un número positivo")
+		return
+	}
+
+	api.writeCompletedSemesters(w, r, input.AlumnID)
+}
+
+func (api *API) writeCompletedSemesters(w http.ResponseWriter, r *http.Request, alumnID int) {
+	if !authorizeAlumn(w, r, alumnID) {
 		return
 	}
 
 	// Obtener los semestres completados desde el repositorio
-	completedSemesters, err := api.Repo.GetCompletedSemesters(r.Context(), input.AlumnID)
+	completedSemesters, err := api.Repo.GetCompletedSemesters(r.Context(), alumnID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Alumno no encontrado")
+		return
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error al obtener semestres completados: %v", err), http.StatusInternalServerError)
+		writeRepoError(w, r, err, "Error al obtener semestres completados")
 		return
 	}
 
@@ -319,3 +664,82 @@ func (api *API) GetCompletedSemesters(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(completedSemesters)
 }
+
+func (api *API) AsignarMateriaProfesor(w http.ResponseWriter, r *http.Request) {
+	var input models.TeacherSubjectRequest
+
+	if !api.decodeJSON(w, r, &input) {
+		return
+	}
+
+	// Validar campos requeridos
+	if input.TeacherID <= 0 || input.SubjectID <= 0 {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Los campos 'teacher_id' y 'subject_id' deben ser números positivos")
+		return
+	}
+
+	if err := api.Repo.AssignSubjectToTeacher(r.Context(), input.TeacherID, input.SubjectID); err != nil {
+		writeRepoError(w, r, err, "Error al asignar materia")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.MessageResponse{
+		Message: "Materia asignada al profesor exitosamente",
+	})
+}
+
+func (api *API) GetAlumnCalificaciones(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	api.writeCalificacionesAgrupadas(w, r, alumnID)
+}
+
+func (api *API) GetAlumnPendingGrades(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	api.writePendingGrades(w, r, alumnID)
+}
+
+func (api *API) GetAlumnSemesterCourses(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	api.writeSemesterCourses(w, r, alumnID)
+}
+
+func (api *API) GetAlumnCompletedSemesters(w http.ResponseWriter, r *http.Request) {
+	alumnID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	api.writeCompletedSemesters(w, r, alumnID)
+}
+
+func (api *API) GetCourseSubjects(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	api.writeSubjectsByCourse(w, r, courseID)
+}
+
+// pathID lee el parámetro {id} de la ruta. Responde 400 si no es un entero positivo.
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		writeFieldError(w, r, "id", "El parámetro 'id' de la ruta debe ser un número positivo")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	postgres "alumnos/db"
+	"alumnos/logging"
+	"alumnos/models"
+	"alumnos/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	checkOK     = "ok"
+	checkFailed = "failed"
+
+	// readinessTimeout limita cada verificación para que la sonda no quede colgada
+	readinessTimeout = 2 * time.Second
+)
+
+// Healthz indica que el proceso responde. No consulta la base de datos: si
+// esta cae, el servicio sigue vivo en modo degradado y no debe reiniciarse.
+func (api *API) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.HealthResponse{Status: "ok"})
+}
+
+// Readyz indica si el servicio puede atender solicitudes: la base de datos
+// responde, no hay migraciones pendientes y los catálogos están cargados.
+// Responde 503 si alguna verificación falla.
+func (api *API) Readyz(w http.ResponseWriter, r *http.Request) {
+	response := models.ReadinessResponse{Status: "ready"}
+
+	response.Checks.Database = runCheck(r.Context(), func(ctx context.Context) (bool, string, error) {
+		if err := api.Repo.DbPool.Ping(ctx); err != nil {
+			return false, "la base de datos no responde", err
+		}
+		return true, "", nil
+	})
+
+	// Sin base de datos las demás verificaciones fallarían por la misma causa
+	if response.Checks.Database.Status == checkOK {
+		var pending []string
+		response.Checks.Migrations = runCheck(r.Context(), func(ctx context.Context) (bool, string, error) {
+			var err error
+			pending, err = postgres.PendingMigrations(repository.WithOperation(ctx, "db.PendingMigrations"), api.Repo.DbPool)
+			if err != nil {
+				return false, "", err
+			}
+			if len(pending) > 0 {
+				return false, fmt.Sprintf("%d migraciones pendientes", len(pending)), nil
+			}
+			return true, "", nil
+		})
+		response.Checks.Migrations.Pending = pending
+
+		response.Checks.Seeds = runCheck(r.Context(), func(ctx context.Context) (bool, string, error) {
+			semesters, courses, err := api.Repo.CatalogCounts(ctx)
+			if err != nil {
+				return false, "", err
+			}
+			var empty []string
+			if semesters == 0 {
+				empty = append(empty, "cat_semesters")
+			}
+			if courses == 0 {
+				empty = append(empty, "cat_courses")
+			}
+			if len(empty) > 0 {
+				return false, "catálogos vacíos: " + strings.Join(empty, ", "), nil
+			}
+			return true, fmt.Sprintf("%d semestres, %d carreras", semesters, courses), nil
+		})
+	} else {
+		skipped := models.CheckResult{Status: checkFailed, Message: "la base de datos no responde"}
+		response.Checks.Migrations = skipped
+		response.Checks.Seeds = skipped
+	}
+
+	stat := api.Repo.DbPool.Stat()
+	response.Pool = models.PoolStats{
+		MaxConns:          stat.MaxConns(),
+		TotalConns:        stat.TotalConns(),
+		IdleConns:         stat.IdleConns(),
+		AcquiredConns:     stat.AcquiredConns(),
+		ConstructingConns: stat.ConstructingConns(),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+		CanceledAcquires:  stat.CanceledAcquireCount(),
+	}
+
+	status := http.StatusOK
+	checks := response.Checks
+	if checks.Database.Status != checkOK || checks.Migrations.Status != checkOK || checks.Seeds.Status != checkOK {
+		response.Status = "not_ready"
+		status = http.StatusServiceUnavailable
+		logging.FromContext(r.Context(), nil).Warn("servicio no disponible", "checks", checks)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// runCheck ejecuta una verificación con límite de tiempo y mide su duración.
+// check devuelve ok=false con un mensaje para los fallos esperados; un error
+// indica que no se pudo verificar y su detalle va al log, no a la respuesta.
+func runCheck(ctx context.Context, check func(context.Context) (ok bool, message string, err error)) models.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	start := time.Now()
+	ok, message, err := check(ctx)
+	result := models.CheckResult{
+		Status:    checkOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Message:   message,
+	}
+	if err != nil {
+		logging.FromContext(ctx, nil).Warn("verificación de disponibilidad fallida", "error", err)
+		if result.Message == "" {
+			result.Message = "no se pudo verificar; ver el log del servidor"
+		}
+	}
+	if !ok || err != nil {
+		result.Status = checkFailed
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"alumnos/auth"
+	"alumnos/logging"
+	"alumnos/models"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// IdempotencyTTL es cuánto tiempo se repite la respuesta de una clave.
+	IdempotencyTTL = 24 * time.Hour
+	// idempotencyAbandonAfter libera las claves de solicitudes que nunca
+	// terminaron (el servidor se detuvo a la mitad); supera al WriteTimeout por defecto.
+	idempotencyAbandonAfter = 2 * time.Minute
+)
+
+// Las claves suelen ser UUID; se limitan a caracteres seguros para logs.
+var validIdempotencyKey = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,255}$`)
+
+// bodyRecorder copia la respuesta para guardarla mientras se envía al cliente.
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *bodyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *bodyRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *bodyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// Idempotent permite reintentar un POST con el header Idempotency-Key: la
+// primera solicitud se ejecuta y su respuesta se guarda junto con un hash del
+// cuerpo; los reintentos con la misma clave reciben esa respuesta sin volver
+// a ejecutarse, y si el cuerpo es distinto, 409. Las respuestas 5xx no se
+// guardan para que el reintento pueda funcionar. Sin el header, la solicitud
+// se atiende como siempre. Debe ir dentro de RequireAuth: las claves son
+// propias de cada usuario y de cada ruta.
+func (api *API) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if !validIdempotencyKey.MatchString(key) {
+			writeFieldError(w, r, "Idempotency-Key", "El header 'Idempotency-Key' debe tener de 1 a 255 letras, dígitos o . _ : -")
+			return
+		}
+
+		// Leer el cuerpo completo para calcular el hash y dárselo después al handler
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, bodyLimit(api.MaxBodyBytes)))
+		if err != nil {
+			writeDecodeError(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(sum[:])
+
+		scope := r.Pattern
+		if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+			scope += " " + claims.Role + ":" + strconv.Itoa(claims.Subject)
+		}
+
+		stored, err := api.Repo.ClaimIdempotencyKey(r.Context(), scope, key, requestHash, IdempotencyTTL, idempotencyAbandonAfter)
+		if err != nil {
+			writeRepoError(w, r, err, "Error al verificar la clave de idempotencia")
+			return
+		}
+		if stored != nil {
+			replayIdempotent(w, r, stored, requestHash)
+			return
+		}
+
+		rec := &bodyRecorder{ResponseWriter: w}
+		next(rec, r)
+
+		// La respuesta ya se envió; guardarla aunque el cliente se haya desconectado
+		ctx := context.WithoutCancel(r.Context())
+		logger := logging.FromContext(ctx, nil)
+		if rec.status == 0 || rec.status >= 500 {
+			if err := api.Repo.ReleaseIdempotencyKey(ctx, scope, key); err != nil {
+				logger.Warn("no se pudo liberar la clave de idempotencia", "error", err)
+			}
+			return
+		}
+		err = api.Repo.SaveIdempotentResponse(ctx, scope, key, models.StoredResponse{
+			StatusCode:  rec.status,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			// Un reintento recibirá 409 "en curso" hasta que la clave se abandone
+			logger.Error("no se pudo guardar la respuesta idempotente", "error", err)
+		}
+	}
+}
+
+// replayIdempotent responde a un reintento con lo guardado para su clave.
+func replayIdempotent(w http.ResponseWriter, r *http.Request, stored *models.StoredResponse, requestHash string) {
+	switch {
+	case stored.RequestHash != requestHash:
+		writeError(w, r, http.StatusConflict, CodeIdempotencyMismatch,
+			"La Idempotency-Key ya se usó con un cuerpo distinto; use una clave nueva para otra solicitud")
+	case stored.StatusCode == 0:
+		w.Header().Set("Retry-After", "1")
+		writeError(w, r, http.StatusConflict, CodeIdempotencyInProgress,
+			"La solicitud original con esta Idempotency-Key todavía está en curso")
+	default:
+		if stored.ContentType != "" {
+			w.Header().Set("Content-Type", stored.ContentType)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(stored.StatusCode)
+		w.Write(stored.Body)
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"alumnos/auth"
+	"alumnos/db/dbtest"
+	"alumnos/models"
+	"alumnos/repository"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// countingHandler responde status y cuenta cuántas veces se ejecutó.
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(h.status)
+	w.Write(append([]byte(`{"echo":`), append(body, '}')...))
+}
+
+// Las solicitudes que Idempotent rechaza o deja pasar sin consultar la base.
+func TestIdempotentWithoutDatabase(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantCalls  int
+	}{
+		{"sin clave", "", `{}`, http.StatusCreated, "", 1},
+		{"clave con espacios", "mi clave", `{}`, http.StatusBadRequest, CodeBadRequest, 0},
+		{"clave demasiado larga", strings.Repeat("a", 256), `{}`, http.StatusBadRequest, CodeBadRequest, 0},
+		{"cuerpo excedido", "clave-1", `{"x":"` + strings.Repeat("a", 2<<10) + `"}`, http.StatusRequestEntityTooLarge, CodeTooLarge, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			api.MaxBodyBytes = 1 << 10
+			next := &countingHandler{status: http.StatusCreated}
+
+			r := httptest.NewRequest("POST", "/v1/alumnos", strings.NewReader(tt.body))
+			if tt.key != "" {
+				r.Header.Set("Idempotency-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			api.Idempotent(next.ServeHTTP)(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("el handler se ejecutó %d veces, se esperaban %d", next.calls, tt.wantCalls)
+			}
+			if tt.wantCode != "" {
+				if code := errorCode(t, rec); code != tt.wantCode {
+					t.Errorf("code = %q, se esperaba %q", code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayIdempotent(t *testing.T) {
+	stored := models.StoredResponse{RequestHash: "abc", StatusCode: http.StatusCreated, ContentType: "application/json", Body: []byte(`{"id":7}`)}
+	inProgress := models.StoredResponse{RequestHash: "abc"}
+
+	tests := []struct {
+		name       string
+		stored     models.StoredResponse
+		hash       string
+		wantStatus int
+		wantCode   string
+		wantHeader string // header que debe llegar con valor
+	}{
+		{"cuerpo distinto", stored, "otro", http.StatusConflict, CodeIdempotencyMismatch, ""},
+		{"en curso", inProgress, "abc", http.StatusConflict, CodeIdempotencyInProgress, "Retry-After"},
+		{"en curso con cuerpo distinto", inProgress, "otro", http.StatusConflict, CodeIdempotencyMismatch, ""},
+		{"terminada", stored, "abc", http.StatusCreated, "", "Idempotent-Replayed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			replayIdempotent(rec, httptest.NewRequest("POST", "/v1/alumnos", nil), &tt.stored, tt.hash)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantHeader != "" && rec.Header().Get(tt.wantHeader) == "" {
+				t.Errorf("falta el header %s", tt.wantHeader)
+			}
+			if tt.wantCode != "" {
+				if code := errorCode(t, rec); code != tt.wantCode {
+					t.Errorf("code = %q, se esperaba %q", code, tt.wantCode)
+				}
+				return
+			}
+			if got := rec.Body.String(); got != string(tt.stored.Body) {
+				t.Errorf("cuerpo = %q, se esperaba %q", got, tt.stored.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.stored.ContentType {
+				t.Errorf("Content-Type = %q, se esperaba %q", got, tt.stored.ContentType)
+			}
+		})
+	}
+}
+
+// Reintentos contra PostgreSQL: la respuesta se repite, un cuerpo distinto da
+// 409 y un error del servidor libera la clave.
+func TestIdempotentReplay(t *testing.T) {
+	pool := dbtest.Migrated(t)
+	api := newTestAPI(t)
+	api.Repo = repository.NewPgxStorage(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	next := &countingHandler{}
+	handler := api.Idempotent(next.ServeHTTP)
+
+	admin := &auth.Claims{Subject: 1, Role: auth.RoleAdmin}
+	other := &auth.Claims{Subject: 2, Role: auth.RoleAdmin}
+
+	tests := []struct {
+		name         string
+		claims       *auth.Claims
+		key          string
+		body         string
+		status       int // respuesta del handler si se ejecuta
+		wantStatus   int
+		wantCalls    int // ejecuciones acumuladas del handler
+		wantReplayed bool
+	}{
+		{"error del servidor", admin, "k1", `{"a":1}`, http.StatusInternalServerError, http.StatusInternalServerError, 1, false},
+		{"reintento tras el error se ejecuta", admin, "k1", `{"a":1}`, http.StatusCreated, http.StatusCreated, 2, false},
+		{"reintento se repite", admin, "k1", `{"a":1}`, http.StatusCreated, http.StatusCreated, 2, true},
+		{"cuerpo distinto", admin, "k1", `{"a":2}`, http.StatusCreated, http.StatusConflict, 2, false},
+		{"otro usuario con la misma clave", other, "k1", `{"a":1}`, http.StatusCreated, http.StatusCreated, 3, false},
+		{"error de validación también se repite", admin, "k2", `{}`, http.StatusBadRequest, http.StatusBadRequest, 4, false},
+		{"reintento del error de validación", admin, "k2", `{}`, http.StatusCreated, http.StatusBadRequest, 4, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next.status = tt.status
+			r := httptest.NewRequest("POST", "/v1/alumnos", strings.NewReader(tt.body))
+			r.Pattern = "POST /v1/alumnos"
+			r.Header.Set("Idempotency-Key", tt.key)
+			r = r.WithContext(auth.WithClaims(r.Context(), tt.claims))
+			rec := httptest.NewRecorder()
+			handler(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("el handler se ejecutó %d veces, se esperaban %d", next.calls, tt.wantCalls)
+			}
+			if replayed := rec.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.wantReplayed {
+				t.Errorf("Idempotent-Replayed = %v, se esperaba %v", replayed, tt.wantReplayed)
+			}
+			if tt.wantReplayed && rec.Body.String() != `{"echo":`+tt.body+`}` {
+				t.Errorf("cuerpo repetido = %q", rec.Body)
+			}
+		})
+	}
+}
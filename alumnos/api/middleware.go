@@ -0,0 +1,188 @@
+package api
+
+import (
+	"alumnos/auth"
+	"alumnos/logging"
+	"alumnos/ratelimit"
+	"alumnos/tracing"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+type requestIDKey struct{}
+
+// Un X-Request-ID entrante solo se reutiliza si es corto y seguro para logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID asigna un identificador a cada solicitud, lo devuelve en el
+// header X-Request-ID y lo deja en el contexto para logs y respuestas de error.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type callerKey struct{}
+
+// caller lo completa RequireAuth para que LogRequests registre quién hizo la
+// solicitud; los handlers reciben copias de la solicitud, no la original.
+type caller struct {
+	subject int
+	role    string
+}
+
+// statusRecorder guarda el código y los bytes escritos por el handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// RequestObserver recibe cada solicitud terminada junto con el patrón de la
+// ruta que la atendió ("unmatched" si ninguna); lo usan las métricas.
+type RequestObserver func(r *http.Request, route string, status int, elapsed time.Duration)
+
+// LogRequests registra una línea por solicitud y deja en el contexto un
+// logger con el request_id y, si tracing.Middleware abrió un span, el
+// trace_id. Debe ir dentro de RequestID y recibir del ServeMux la misma
+// solicitud (sin WithContext) para leer el patrón de la ruta que la atendió.
+func LogRequests(logger *slog.Logger, next http.Handler, observers ...RequestObserver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestLogger := logger.With("request_id", RequestIDFromContext(r.Context()))
+		if traceID := tracing.TraceID(r.Context()); traceID != "" {
+			requestLogger = requestLogger.With("trace_id", traceID, "span_id", tracing.SpanID(r.Context()))
+		}
+
+		who := &caller{}
+		ctx := logging.WithLogger(r.Context(), requestLogger)
+		r = r.WithContext(context.WithValue(ctx, callerKey{}, who))
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		// ServeMux asigna r.Pattern sobre la misma solicitud que recibe
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+
+		elapsed := time.Since(start)
+		for _, observe := range observers {
+			observe(r, route, rec.status, elapsed)
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int("bytes", rec.bytes),
+			slog.Float64("latency_ms", float64(elapsed.Microseconds())/1000),
+		}
+		if who.role != "" {
+			attrs = append(attrs, slog.Group("caller", slog.Int("id", who.subject), slog.String("role", who.role)))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case route == "GET /readyz" && rec.status == http.StatusServiceUnavailable:
+			level = slog.LevelWarn // Readyz ya registró qué verificación falló
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		case route == "GET /healthz" || route == "GET /readyz" || route == "GET /metrics":
+			level = slog.LevelDebug // las sondas y Prometheus llegan cada pocos segundos
+		}
+		requestLogger.LogAttrs(r.Context(), level, "solicitud atendida", attrs...)
+	})
+}
+
+// unlimitedPaths quedan fuera del límite por IP: las sondas de docker y
+// Prometheus llegan siempre desde las mismas direcciones internas.
+var unlimitedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// LimitRequests aplica el límite de solicitudes por IP del cliente y el tamaño
+// máximo del cuerpo a todas las rutas. Se coloca entre LogRequests y el
+// ServeMux y le pasa la misma solicitud para no ocultarle r.Pattern. Un
+// maxBody no positivo usa DefaultMaxBodyBytes.
+func LimitRequests(ipLimiter *ratelimit.Limiter, proxies ratelimit.TrustedProxies, maxBody int64, next http.Handler) http.Handler {
+	maxBody = bodyLimit(maxBody)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !unlimitedPaths[r.URL.Path] {
+			if ok, retryAfter := ipLimiter.Allow(proxies.ClientIP(r)); !ok {
+				writeRateLimited(w, r, retryAfter)
+				return
+			}
+		}
+
+		// Un Content-Length declarado mayor al límite se rechaza sin leer el cuerpo
+		if r.ContentLength > maxBody {
+			writeError(w, r, http.StatusRequestEntityTooLarge, CodeTooLarge,
+				fmt.Sprintf("El cuerpo de la solicitud excede %d bytes", maxBody))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recordCaller anota al usuario autenticado para el log de la solicitud.
+func recordCaller(ctx context.Context, claims *auth.Claims) {
+	if who, ok := ctx.Value(callerKey{}).(*caller); ok {
+		who.subject = claims.Subject
+		who.role = claims.Role
+	}
+}
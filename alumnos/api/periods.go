@@ -0,0 +1,60 @@
+package api
+
+import (
+	"alumnos/models"
+	"alumnos/repository"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+func (api *API) GetAcademicPeriods(w http.ResponseWriter, r *http.Request) {
+	periods, err := api.Repo.GetAcademicPeriods(r.Context())
+	if err != nil {
+		writeRepoError(w, r, err, "Error al obtener periodos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(periods)
+}
+
+func (api *API) CrearPeriodo(w http.ResponseWriter, r *http.Request) {
+	var request models.AcademicPeriodRequest
+	if !api.decodeJSON(w, r, &request) {
+		return
+	}
+
+	// Validar campos requeridos
+	if request.Code == "" || request.StartDate == "" || request.EndDate == "" {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Faltan campos requeridos (code, start_date, end_date)")
+		return
+	}
+
+	start, errStart := time.Parse(time.DateOnly, request.StartDate)
+	end, errEnd := time.Parse(time.DateOnly, request.EndDate)
+	if errStart != nil || errEnd != nil {
+		writeError(w, r, http.StatusBadRequest, CodeBadRequest, "Los campos 'start_date' y 'end_date' deben tener el formato YYYY-MM-DD")
+		return
+	}
+	if !end.After(start) {
+		writeFieldError(w, r, "end_date", "El campo 'end_date' debe ser posterior a 'start_date'")
+		return
+	}
+
+	period, err := api.Repo.CreateAcademicPeriod(r.Context(), request.Code, start, end)
+	if errors.Is(err, repository.ErrDuplicatePeriodCode) || errors.Is(err, repository.ErrPeriodOverlap) {
+		writeError(w, r, http.StatusConflict, CodeConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeRepoError(w, r, err, "Error al crear periodo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(period)
+}
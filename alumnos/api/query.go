@@ -0,0 +1,116 @@
+package api
+
+import (
+	"alumnos/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// decodeJSON decodifica el cuerpo en dst rechazando campos desconocidos,
+// cuerpos mayores a api.MaxBodyBytes y datos sobrantes después del objeto.
+// Si falla, ya respondió al cliente y devuelve false.
+func (api *API) decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, bodyLimit(api.MaxBodyBytes))
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		writeDecodeError(w, r, err)
+		return false
+	}
+
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidJSON, "El cuerpo debe contener un único objeto JSON")
+		return false
+	}
+
+	return true
+}
+
+// parseStudentFilter lee los parámetros de consulta de GET /v1/students:
+// limit, offset, course_id, current_semester, created_from, created_to
+// (YYYY-MM-DD, ambos inclusivos) y sort (id, lastname, -id, -lastname).
+func parseStudentFilter(query url.Values) (models.StudentFilter, error) {
+	filter := models.StudentFilter{Limit: defaultPageLimit, Sort: "id"}
+
+	var err error
+	if filter.Limit, err = queryInt(query, "limit", defaultPageLimit); err != nil {
+		return filter, err
+	}
+	if filter.Limit < 1 || filter.Limit > maxPageLimit {
+		return filter, fmt.Errorf("El parámetro 'limit' debe estar entre 1 y %d", maxPageLimit)
+	}
+
+	if filter.Offset, err = queryInt(query, "offset", 0); err != nil {
+		return filter, err
+	}
+	if filter.Offset < 0 {
+		return filter, fmt.Errorf("El parámetro 'offset' no puede ser negativo")
+	}
+
+	if filter.CourseID, err = queryInt(query, "course_id", 0); err != nil {
+		return filter, err
+	}
+	if filter.CurrentSemester, err = queryInt(query, "current_semester", 0); err != nil {
+		return filter, err
+	}
+
+	if filter.CreatedFrom, err = queryDate(query, "created_from"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = queryDate(query, "created_to"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo != nil {
+		// created_to es inclusivo: se filtra por menor que el día siguiente
+		next := filter.CreatedTo.AddDate(0, 0, 1)
+		filter.CreatedTo = &next
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		filter.Desc = strings.HasPrefix(sort, "-")
+		filter.Sort = strings.TrimPrefix(sort, "-")
+		if filter.Sort != "id" && filter.Sort != "lastname" {
+			return filter, fmt.Errorf("El parámetro 'sort' debe ser 'id' o 'lastname', con '-' opcional para orden descendente")
+		}
+	}
+
+	return filter, nil
+}
+
+func queryInt(query url.Values, name string, fallback int) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("El parámetro '%s' debe ser un número entero", name)
+	}
+	return value, nil
+}
+
+func queryDate(query url.Values, name string) (*time.Time, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, fmt.Errorf("El parámetro '%s' debe tener el formato YYYY-MM-DD", name)
+	}
+	return &value, nil
+}
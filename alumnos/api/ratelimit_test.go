@@ -0,0 +1,154 @@
+package api
+
+import (
+	"alumnos/auth"
+	"alumnos/ratelimit"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// chunked oculta el tamaño del cuerpo, como una solicitud sin Content-Length.
+type chunked struct{ io.Reader }
+
+func TestLimitRequests(t *testing.T) {
+	api := newTestAPI(t)
+	handler := LimitRequests(ratelimit.New(0.001, 1), nil, 1<<10, newTestMux(api))
+	big := `{"email": "` + strings.Repeat("a", 2<<10) + `"}`
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		remote     string
+		body       io.Reader
+		wantStatus int
+		wantCode   string
+	}{
+		{"primera solicitud", "POST", "/v1/auth/login", "203.0.113.1:1", strings.NewReader(`{}`), http.StatusBadRequest, CodeBadRequest},
+		{"sin fichas", "POST", "/v1/auth/login", "203.0.113.1:1", strings.NewReader(`{}`), http.StatusTooManyRequests, CodeRateLimited},
+		{"otra IP", "POST", "/v1/auth/login", "203.0.113.2:1", strings.NewReader(`{}`), http.StatusBadRequest, CodeBadRequest},
+		{"health check sin límite", "GET", "/healthz", "203.0.113.1:1", nil, http.StatusOK, ""},
+		{"Content-Length excedido", "POST", "/v1/auth/login", "203.0.113.3:1", strings.NewReader(big), http.StatusRequestEntityTooLarge, CodeTooLarge},
+		{"cuerpo sin Content-Length excedido", "POST", "/v1/auth/login", "203.0.113.4:1", chunked{strings.NewReader(big)}, http.StatusRequestEntityTooLarge, CodeTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, tt.body)
+			r.RemoteAddr = tt.remote
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			if code := errorCode(t, rec); code != tt.wantCode {
+				t.Errorf("code = %q, se esperaba %q", code, tt.wantCode)
+			}
+			if tt.wantStatus == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+				t.Error("falta el header Retry-After")
+			}
+		})
+	}
+}
+
+func TestUserRateLimit(t *testing.T) {
+	api := newTestAPI(t)
+	api.UserLimiter = ratelimit.New(0.001, 1)
+	mux := newTestMux(api)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"primera solicitud", bearer(t, api, 2, auth.RoleAdmin), http.StatusBadRequest},
+		{"sin fichas", bearer(t, api, 2, auth.RoleAdmin), http.StatusTooManyRequests},
+		{"otro usuario", bearer(t, api, 3, auth.RoleAdmin), http.StatusBadRequest},
+		// El límite se aplica antes de verificar el rol
+		{"mismo id con otro rol", bearer(t, api, 2, auth.RoleTeacher), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/v1/alumnos", strings.NewReader(`{}`))
+			r.Header.Set("Authorization", tt.token)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+// decodeJSON usa el límite configurado en la API, no uno fijo.
+func TestDecodeJSONBodyLimit(t *testing.T) {
+	// Más de 1 MiB, el límite fijo que usaba decodeJSON
+	body := `{"email": "` + strings.Repeat("a", 3<<19) + `", "desconocido": 1}`
+
+	tests := []struct {
+		name       string
+		limit      int64
+		wantStatus int
+		wantCode   string
+	}{
+		{"límite menor al cuerpo", 1 << 10, http.StatusRequestEntityTooLarge, CodeTooLarge},
+		{"límite mayor al cuerpo", 4 << 20, http.StatusBadRequest, CodeInvalidJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			api.MaxBodyBytes = tt.limit
+			rec := httptest.NewRecorder()
+			newTestMux(api).ServeHTTP(rec, httptest.NewRequest("POST", "/v1/auth/login", strings.NewReader(body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if code := errorCode(t, rec); code != tt.wantCode {
+				t.Errorf("code = %q, se esperaba %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// Un límite sin configurar (0) o negativo usa el predeterminado en lugar de
+// rechazar cualquier cuerpo.
+func TestBodyLimitDefault(t *testing.T) {
+	for _, limit := range []int64{0, -1} {
+		t.Run(fmt.Sprint(limit), func(t *testing.T) {
+			api := NewAPI(nil, nil, Options{MaxBodyBytes: limit})
+			if api.MaxBodyBytes != DefaultMaxBodyBytes {
+				t.Errorf("MaxBodyBytes = %d, se esperaba %d", api.MaxBodyBytes, DefaultMaxBodyBytes)
+			}
+
+			// El campo también se puede dejar en cero después de construir la API
+			api = newTestAPI(t)
+			api.MaxBodyBytes = limit
+			handler := LimitRequests(ratelimit.New(0, 0), nil, limit, newTestMux(api))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/auth/login", strings.NewReader(`{"email": "ana@example.com"}`)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, se esperaba %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+			}
+			if code := errorCode(t, rec); code != CodeBadRequest {
+				t.Errorf("code = %q, se esperaba %q", code, CodeBadRequest)
+			}
+		})
+	}
+
+	if api := NewAPI(nil, nil, Options{MaxBodyBytes: 2048}); api.MaxBodyBytes != 2048 {
+		t.Errorf("MaxBodyBytes = %d, se esperaba 2048", api.MaxBodyBytes)
+	}
+}
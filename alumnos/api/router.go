@@ -1,31 +1,105 @@
 package api
 
-import "net/http"
+import (
+	"alumnos/auth"
+	"alumnos/openapi"
+	"net/http"
+	"strconv"
+	"time"
+)
 
-func RegisterRoutes(mux *http.ServeMux, apiInstance *API) {
-	// Rutas para alumnos
-	mux.Handle("POST /v1/alumnos", http.HandlerFunc(apiInstance.RegistrarAlumno))
+// legacyReadsDeprecated es la fecha en que las lecturas POST con el ID en el
+// cuerpo quedaron obsoletas al publicarse sus equivalentes GET.
+var legacyReadsDeprecated = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// legacyReadsSunset es la fecha en que se retirarán esas rutas; cero mientras
+// no haya una fecha acordada con los clientes.
+var legacyReadsSunset time.Time
+
+// Route asocia un patrón de http.ServeMux con su handler ya protegido.
+type Route struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// Routes devuelve todas las rutas de la API. La especificación OpenAPI debe
+// documentar cada patrón de esta lista (ver TestOpenAPISpec).
+func Routes(apiInstance *API) []Route {
+	return []Route{
+		// Rutas de autenticación
+		{"POST /v1/auth/login", http.HandlerFunc(apiInstance.Login)},
+		{"POST /v1/auth/alumnos/login", http.HandlerFunc(apiInstance.LoginAlumno)},
 
-	// Rutas para semestres y materias
-	mux.Handle("POST /v1/semestres", http.HandlerFunc(apiInstance.RegistrarEnSemestre))
+		// Rutas para alumnos
+		{"POST /v1/alumnos", apiInstance.Protect(apiInstance.Idempotent(apiInstance.RegistrarAlumno), auth.RoleAdmin)},
+		{"GET /v1/alumnos/search", apiInstance.Protect(apiInstance.SearchStudents, auth.RoleTeacher, auth.RoleAdmin)},
+		{"GET /v1/alumnos/{id}", apiInstance.Protect(apiInstance.GetAlumno)},
+		{"PATCH /v1/alumnos/{id}", apiInstance.Protect(apiInstance.ActualizarAlumno, auth.RoleAdmin)},
+		{"DELETE /v1/alumnos/{id}", apiInstance.Protect(apiInstance.EliminarAlumno, auth.RoleAdmin)},
+		{"POST /v1/alumnos/{id}/restore", apiInstance.Protect(apiInstance.RestaurarAlumno, auth.RoleAdmin)},
+		{"GET /v1/alumnos/{id}/calificaciones", apiInstance.Protect(apiInstance.GetAlumnCalificaciones)},
+		{"GET /v1/alumnos/{id}/pending-grades", apiInstance.Protect(apiInstance.GetAlumnPendingGrades)},
+		{"GET /v1/alumnos/{id}/semester-courses", apiInstance.Protect(apiInstance.GetAlumnSemesterCourses)},
+		{"GET /v1/alumnos/{id}/completed-semesters", apiInstance.Protect(apiInstance.GetAlumnCompletedSemesters)},
 
-	mux.Handle("POST /v1/alumnos/pending-grades", http.HandlerFunc(apiInstance.GetPendingGradesHandler))
+		// Rutas para semestres y materias
+		{"POST /v1/semestres", apiInstance.Protect(apiInstance.Idempotent(apiInstance.RegistrarEnSemestre), auth.RoleAdmin)},
 
-	// Rutas para calificaciones parciales
-	mux.Handle("POST /v1/calificaciones/parcial", http.HandlerFunc(apiInstance.RegistrarCalificacionParcial))
+		// Rutas para calificaciones parciales
+		{"POST /v1/calificaciones/parcial", apiInstance.Protect(apiInstance.RegistrarCalificacionParcial, auth.RoleTeacher, auth.RoleAdmin)},
 
-	// Rutas para generar calificaciones agrupadas
-	mux.Handle("POST /v1/calificaciones/agrupadas", http.HandlerFunc(apiInstance.GenerarCalificacionesAgrupadas))
+		// Catálogos de carreras, materias y semestres
+		{"GET /v1/courses", http.HandlerFunc(apiInstance.GetCourses)},
+		{"GET /v1/courses/{id}/subjects", http.HandlerFunc(apiInstance.GetCourseSubjects)},
+		{"POST /v1/courses/{id}/subjects", apiInstance.Protect(apiInstance.CrearMateria, auth.RoleAdmin)},
+		{"PATCH /v1/subjects/{id}", apiInstance.Protect(apiInstance.ActualizarMateria, auth.RoleAdmin)},
+		{"POST /v1/subjects/{id}/retire", apiInstance.Protect(apiInstance.RetirarMateria, auth.RoleAdmin)},
+		{"DELETE /v1/subjects/{id}", apiInstance.Protect(apiInstance.EliminarMateria, auth.RoleAdmin)},
+		{"GET /v1/students", apiInstance.Protect(apiInstance.GetStudents, auth.RoleTeacher, auth.RoleAdmin)},
+		{"GET /v1/semesters", http.HandlerFunc(apiInstance.GetCatSemesters)},
 
-	mux.Handle("POST /v1/courses/subjects", http.HandlerFunc(apiInstance.GetSubjectsByCourse))
+		// Rutas para periodos académicos
+		{"GET /v1/periods", http.HandlerFunc(apiInstance.GetAcademicPeriods)},
+		{"POST /v1/periods", apiInstance.Protect(apiInstance.CrearPeriodo, auth.RoleAdmin)},
 
-	mux.Handle("GET /v1/courses", http.HandlerFunc(apiInstance.GetCourses))
+		// Rutas de administración
+		{"POST /v1/teacher-subjects", apiInstance.Protect(apiInstance.AsignarMateriaProfesor, auth.RoleAdmin)},
 
-	mux.Handle("GET /v1/students", http.HandlerFunc(apiInstance.GetStudents))
+		// Lecturas antiguas con el ID en el cuerpo JSON; se mantienen por compatibilidad
+		{"POST /v1/alumnos/pending-grades", deprecated("/v1/alumnos/{id}/pending-grades", apiInstance.Protect(apiInstance.GetPendingGradesHandler))},
+		{"POST /v1/calificaciones/agrupadas", deprecated("/v1/alumnos/{id}/calificaciones", apiInstance.Protect(apiInstance.GenerarCalificacionesAgrupadas))},
+		{"POST /v1/courses/subjects", deprecated("/v1/courses/{id}/subjects", http.HandlerFunc(apiInstance.GetSubjectsByCourse))},
+		{"POST /v1/semester-courses", deprecated("/v1/alumnos/{id}/semester-courses", apiInstance.Protect(apiInstance.GetSemesterCoursesByAlumnId))},
+		{"POST /v1/completed-semesters", deprecated("/v1/alumnos/{id}/completed-semesters", apiInstance.Protect(apiInstance.GetCompletedSemesters))},
 
-	mux.Handle("POST /v1/semester-courses", http.HandlerFunc(apiInstance.GetSemesterCoursesByAlumnId))
+		// Sondas de docker y del proxy
+		{"GET /healthz", http.HandlerFunc(apiInstance.Healthz)},
+		{"GET /readyz", http.HandlerFunc(apiInstance.Readyz)},
+		{"GET /metrics", apiInstance.Metrics.Handler()},
 
-	mux.Handle("GET /v1/semesters", http.HandlerFunc(apiInstance.GetCatSemesters))
+		// Documentación de la API
+		{"GET /openapi.json", http.HandlerFunc(openapi.ServeSpec)},
+		{"GET /docs", http.HandlerFunc(openapi.ServeDocs)},
+	}
+}
+
+func RegisterRoutes(mux *http.ServeMux, apiInstance *API) {
+	for _, route := range Routes(apiInstance) {
+		mux.Handle(route.Pattern, route.Handler)
+	}
+}
 
-	mux.Handle("POST /v1/completed-semesters", http.HandlerFunc(apiInstance.GetCompletedSemesters))
+// deprecated marca la respuesta con el header Deprecation de RFC 9745
+// (@ seguido de la fecha en segundos Unix), agrega Sunset (RFC 8594) si hay
+// fecha de retiro y enlaza la ruta GET que la reemplaza.
+func deprecated(successor string, next http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(legacyReadsDeprecated.Unix(), 10)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", deprecation)
+		if !legacyReadsSunset.IsZero() {
+			w.Header().Set("Sunset", legacyReadsSunset.UTC().Format(http.TimeFormat))
+		}
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
 }
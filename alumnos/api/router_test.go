@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeprecatedRoutes(t *testing.T) {
+	legacy := map[string]string{
+		"/v1/alumnos/pending-grades":   "</v1/alumnos/{id}/pending-grades>; rel=\"successor-version\"",
+		"/v1/calificaciones/agrupadas": "</v1/alumnos/{id}/calificaciones>; rel=\"successor-version\"",
+		"/v1/courses/subjects":         "</v1/courses/{id}/subjects>; rel=\"successor-version\"",
+		"/v1/semester-courses":         "</v1/alumnos/{id}/semester-courses>; rel=\"successor-version\"",
+		"/v1/completed-semesters":      "</v1/alumnos/{id}/completed-semesters>; rel=\"successor-version\"",
+	}
+	mux := newTestMux(newTestAPI(t))
+
+	for path, link := range legacy {
+		t.Run(path, func(t *testing.T) {
+			// Sin token ni cuerpo: el header se agrega aunque la solicitud falle
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest("POST", path, nil))
+
+			if got := rec.Header().Get("Deprecation"); got != "@1792281600" {
+				t.Errorf("Deprecation = %q, se esperaba @1792281600", got)
+			}
+			if got := rec.Header().Get("Sunset"); got != "" {
+				t.Errorf("Sunset = %q, no se esperaba sin fecha de retiro", got)
+			}
+			if got := rec.Header().Get("Link"); got != link {
+				t.Errorf("Link = %q, se esperaba %q", got, link)
+			}
+		})
+	}
+}
+
+func TestDeprecatedSunset(t *testing.T) {
+	defer func(sunset time.Time) { legacyReadsSunset = sunset }(legacyReadsSunset)
+	legacyReadsSunset = time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	rec := httptest.NewRecorder()
+	deprecated("/v1/courses/{id}/subjects", next).ServeHTTP(rec, httptest.NewRequest("POST", "/v1/courses/subjects", nil))
+
+	if got := rec.Header().Get("Sunset"); got != "Mon, 01 Mar 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q, se esperaba Mon, 01 Mar 2027 00:00:00 GMT", got)
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"alumnos/models"
+	"alumnos/repository"
+	"alumnos/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (api *API) CrearMateria(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.SubjectRequest
+	if !api.decodeJSON(w, r, &request) {
+		return
+	}
+
+	// Al crear, todos los campos son obligatorios
+	v := validation.New()
+	validateSubjectRequest(v, request, true)
+	if !v.Valid() {
+		writeValidationError(w, r, v.Errors())
+		return
+	}
+
+	subject, err := api.Repo.CreateSubject(r.Context(), courseID, request)
+	if err != nil {
+		writeSubjectError(w, r, err, "Error al crear materia")
+		return
+	}
+	// El trigger también avisa, pero así este servidor responde el cambio de inmediato
+	api.Catalog.Invalidate()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subject)
+}
+
+func (api *API) ActualizarMateria(w http.ResponseWriter, r *http.Request) {
+	subjectID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var request models.SubjectRequest
+	if !api.decodeJSON(w, r, &request) {
+		return
+	}
+
+	v := validation.New()
+	validateSubjectRequest(v, request, false)
+	if !v.Valid() {
+		writeValidationError(w, r, v.Errors())
+		return
+	}
+
+	subject, err := api.Repo.UpdateSubject(r.Context(), subjectID, request)
+	if err != nil {
+		writeSubjectError(w, r, err, "Error al actualizar materia")
+		return
+	}
+	api.Catalog.Invalidate()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(subject)
+}
+
+func (api *API) RetirarMateria(w http.ResponseWriter, r *http.Request) {
+	subjectID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.Repo.RetireSubject(r.Context(), subjectID); err != nil {
+		writeSubjectError(w, r, err, "Error al dar de baja materia")
+		return
+	}
+	api.Catalog.Invalidate()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.MessageResponse{
+		Message: "Materia dada de baja exitosamente",
+	})
+}
+
+func (api *API) EliminarMateria(w http.ResponseWriter, r *http.Request) {
+	subjectID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.Repo.DeleteSubject(r.Context(), subjectID); err != nil {
+		writeSubjectError(w, r, err, "Error al eliminar materia")
+		return
+	}
+	api.Catalog.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// maxSubjectText es el largo de key y name en academyc_history (VARCHAR(255)).
+const maxSubjectText = 255
+
+// validateSubjectRequest revisa los campos enviados. Al crear todos son
+// obligatorios; al actualizar, los ausentes conservan su valor.
+func validateSubjectRequest(v *validation.Validator, request models.SubjectRequest, create bool) {
+	text := func(field string, value *string) {
+		if value == nil {
+			v.Check(!create, field, "es obligatorio")
+			return
+		}
+		if v.Check(*value != "", field, "no puede quedar vacío") {
+			v.Check(utf8.RuneCountInString(*value) <= maxSubjectText, field,
+				fmt.Sprintf("debe tener como máximo %d caracteres", maxSubjectText))
+		}
+	}
+	text("key", request.Key)
+	text("name", request.Name)
+
+	if request.Coins == nil {
+		v.Check(!create, "coins", "es obligatorio")
+		return
+	}
+	v.Check(*request.Coins > 0, "coins", "debe ser un número positivo")
+}
+
+func writeSubjectError(w http.ResponseWriter, r *http.Request, err error, prefix string) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "Materia o curso no encontrado")
+	case errors.Is(err, repository.ErrDuplicateSubjectKey):
+		writeError(w, r, http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, repository.ErrSubjectInUse):
+		writeError(w, r, http.StatusConflict, CodeConflict, "La materia ya fue cursada por alumnos; use la baja (retire) en lugar de eliminarla")
+	default:
+		writeRepoError(w, r, err, prefix)
+	}
+}
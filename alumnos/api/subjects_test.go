@@ -0,0 +1,152 @@
+package api
+
+import (
+	"alumnos/auth"
+	"alumnos/db/dbtest"
+	"alumnos/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestSubjectValidation(t *testing.T) {
+	long := strings.Repeat("a", 256)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantFields []string
+	}{
+		{"crear sin campos", "POST", "/v1/courses/1/subjects", `{}`, []string{"key", "name", "coins"}},
+		{"crear con campos vacíos", "POST", "/v1/courses/1/subjects", `{"key":"","name":"","coins":0}`, []string{"key", "name", "coins"}},
+		{"crear con clave larga", "POST", "/v1/courses/1/subjects", `{"key":"` + long + `","name":"Física","coins":8}`, []string{"key"}},
+		{"crear con créditos negativos", "POST", "/v1/courses/1/subjects", `{"key":"FIS1","name":"Física","coins":-1}`, []string{"coins"}},
+		{"actualizar a nombre vacío", "PATCH", "/v1/subjects/1", `{"name":""}`, []string{"name"}},
+		{"actualizar con nombre largo y créditos en cero", "PATCH", "/v1/subjects/1", `{"name":"` + long + `","coins":0}`, []string{"name", "coins"}},
+	}
+
+	api := newTestAPI(t)
+	mux := newTestMux(api)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("Authorization", bearer(t, api, 1, auth.RoleAdmin))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, r)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, se esperaba %d", rec.Code, http.StatusBadRequest)
+			}
+			var body ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("la respuesta no es un ErrorResponse: %v", err)
+			}
+			if body.Error.Code != CodeValidation {
+				t.Errorf("code = %q, se esperaba %q", body.Error.Code, CodeValidation)
+			}
+			var fields []string
+			for _, field := range body.Error.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("campos = %v, se esperaba %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestWriteSubjectError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"no encontrada", fmt.Errorf("error al obtener materia 1: %w", pgx.ErrNoRows), http.StatusNotFound, CodeNotFound},
+		{"clave repetida", fmt.Errorf("%w: MAT1", repository.ErrDuplicateSubjectKey), http.StatusConflict, CodeConflict},
+		{"materia cursada", repository.ErrSubjectInUse, http.StatusConflict, CodeConflict},
+		{"otra restricción única", &pgconn.PgError{Code: pgUniqueViolation}, http.StatusConflict, CodeConflict},
+		{"llave foránea", &pgconn.PgError{Code: pgForeignKeyViolation}, http.StatusUnprocessableEntity, CodeUnprocessable},
+		{"regla de la tabla", &pgconn.PgError{Code: pgCheckViolation}, http.StatusUnprocessableEntity, CodeUnprocessable},
+		{"error desconocido", errors.New("falla"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeSubjectError(rec, httptest.NewRequest("POST", "/v1/courses/1/subjects", nil), tt.err, "Error al crear materia")
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, se esperaba %d", rec.Code, tt.wantStatus)
+			}
+			if code := errorCode(t, rec); code != tt.wantCode {
+				t.Errorf("code = %q, se esperaba %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSubjectConflicts(t *testing.T) {
+	pool := dbtest.Migrated(t)
+	api := newTestAPI(t)
+	api.Repo = repository.NewPgxStorage(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	mux := newTestMux(api)
+
+	if _, err := pool.Exec(context.Background(), `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO cat_semesters (id, name) VALUES (1, 'Primer semestre');
+		INSERT INTO academyc_history (id, course_id, key, name, coins) VALUES
+			(1, 1, 'MAT1', 'Matemáticas I', 8),
+			(2, 1, 'PRG1', 'Programación I', 10);
+		INSERT INTO alumn (id, name, lastname1, course_id, current_semester) VALUES (1, 'Ana', 'García', 1, 1);
+		INSERT INTO semester_course (alumn_id, semester_id, subject_id) VALUES (1, 1, 1);
+	`); err != nil {
+		t.Fatalf("error al preparar datos: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"crear con clave repetida", "POST", "/v1/courses/1/subjects", `{"key":"MAT1","name":"Otra","coins":6}`, http.StatusConflict, CodeConflict},
+		{"cambiar a una clave ocupada", "PATCH", "/v1/subjects/2", `{"key":"MAT1"}`, http.StatusConflict, CodeConflict},
+		{"borrar una materia cursada", "DELETE", "/v1/subjects/1", ``, http.StatusConflict, CodeConflict},
+		{"crear en una carrera inexistente", "POST", "/v1/courses/99/subjects", `{"key":"FIS1","name":"Física","coins":8}`, http.StatusNotFound, CodeNotFound},
+		{"borrar una materia sin alumnos", "DELETE", "/v1/subjects/2", ``, http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("Authorization", bearer(t, api, 1, auth.RoleAdmin))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, se esperaba %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode != "" {
+				if code := errorCode(t, rec); code != tt.wantCode {
+					t.Errorf("code = %q, se esperaba %q", code, tt.wantCode)
+				}
+			}
+		})
+	}
+}
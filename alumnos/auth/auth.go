@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Roles reconocidos por el sistema
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+var (
+	ErrInvalidToken = errors.New("token inválido")
+	ErrExpiredToken = errors.New("token expirado")
+)
+
+// Claims es el contenido firmado de un token de sesión. Subject es el ID
+// del alumno cuando Role es RoleStudent y el ID del profesor en otro caso.
+type Claims struct {
+	Subject   int    `json:"sub"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Manager firma y verifica tokens de sesión con HMAC-SHA256.
+type Manager struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewManager(secret string, ttl time.Duration) *Manager {
+	return &Manager{secret: []byte(secret), ttl: ttl}
+}
+
+func (m *Manager) IssueToken(subject int, role string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(m.ttl)
+	payload, err := json.Marshal(Claims{
+		Subject:   subject,
+		Role:      role,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error al codificar el token: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + m.sign(encoded), expiresAt, nil
+}
+
+func (m *Manager) ParseToken(token string) (*Claims, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidToken
+	}
+
+	// Comparar en tiempo constante para no filtrar la firma
+	if !hmac.Equal([]byte(signature), []byte(m.sign(encoded))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func (m *Manager) sign(encoded string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error al generar hash de contraseña: %w", err)
+	}
+	return string(hash), nil
+}
+
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// DummyHash es un hash bcrypt con el mismo costo que HashPassword de una
+// contraseña aleatoria que nadie conoce. Los logins lo comparan cuando el
+// correo no existe para tardar lo mismo que con una contraseña incorrecta y
+// no revelar qué correos están registrados.
+const DummyHash = "$2a$10$DrTTr.CUCUeDZixSx17y5u.f0hKzla9xvHoxAgn7FEXCUJcQIMuAu"
+
+type contextKey struct{}
+
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// HasRole indica si el llamador tiene alguno de los roles indicados.
+func (c *Claims) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if c.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimsFromContext devuelve los claims colocados por el middleware de autenticación.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParseToken(t *testing.T) {
+	manager := NewManager("secreto", time.Hour)
+	valid, _, err := manager.IssueToken(7, RoleTeacher)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	expired, _, err := NewManager("secreto", -time.Minute).IssueToken(7, RoleTeacher)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	otherSecret, _, err := NewManager("otro", time.Hour).IssueToken(7, RoleTeacher)
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	payload, signature, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"válido", valid, nil},
+		{"expirado", expired, ErrExpiredToken},
+		{"firmado con otro secreto", otherSecret, ErrInvalidToken},
+		{"sin firma", payload, ErrInvalidToken},
+		{"firma alterada", payload + "." + strings.ToUpper(signature), ErrInvalidToken},
+		{"contenido alterado", "e30." + signature, ErrInvalidToken},
+		{"vacío", "", ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := manager.ParseToken(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseToken() error = %v, se esperaba %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (claims.Subject != 7 || claims.Role != RoleTeacher) {
+				t.Errorf("ParseToken() = %+v, se esperaba el sujeto 7 con rol %s", claims, RoleTeacher)
+			}
+		})
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correcta")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{"contraseña correcta", hash, "correcta", true},
+		{"contraseña incorrecta", hash, "incorrecta", false},
+		{"hash vacío", "", "correcta", false},
+		{"hash de relleno", DummyHash, "correcta", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckPassword(tt.hash, tt.password); got != tt.want {
+				t.Errorf("CheckPassword() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Con otro costo, el login de un correo inexistente tardaría distinto que el
+// de uno registrado.
+func TestDummyHashCost(t *testing.T) {
+	cost, err := bcrypt.Cost([]byte(DummyHash))
+	if err != nil {
+		t.Fatalf("DummyHash no es un hash bcrypt: %v", err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Errorf("DummyHash tiene costo %d; HashPassword usa %d", cost, bcrypt.DefaultCost)
+	}
+}
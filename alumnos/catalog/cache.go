@@ -0,0 +1,110 @@
+// Package catalog guarda en memoria las respuestas de los catálogos (carreras,
+// materias y semestres), que cambian pocas veces al año, para no consultar
+// PostgreSQL en cada carga de página.
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Entry es una respuesta JSON ya serializada junto con sus validadores HTTP.
+type Entry struct {
+	Body         []byte
+	ETag         string    // ETag fuerte: hash del cuerpo
+	LastModified time.Time // último cambio conocido de los catálogos
+}
+
+// Cache guarda una Entry por clave hasta que Invalidate la descarta. Un Cache
+// nil no guarda nada y carga en cada llamada.
+type Cache struct {
+	mu         sync.Mutex
+	entries    map[string]*Entry
+	generation uint64    // aumenta con cada Invalidate
+	changedAt  time.Time // momento de la última invalidación
+}
+
+func New() *Cache {
+	return &Cache{entries: make(map[string]*Entry), changedAt: time.Now()}
+}
+
+// Get devuelve la entrada de key o la construye serializando lo que devuelve
+// load. Los resultados vacíos no se guardan: las claves incluyen IDs que
+// envía el cliente y una carrera inexistente no debe ocupar memoria.
+func (c *Cache) Get(ctx context.Context, key string, load func(context.Context) (any, error)) (*Entry, error) {
+	var generation uint64
+	var changedAt time.Time
+	if c != nil {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		generation, changedAt = c.generation, c.changedAt
+		c.mu.Unlock()
+		if ok {
+			return entry, nil
+		}
+	} else {
+		changedAt = time.Now()
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := newEntry(value, changedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil && !isEmpty(entry.Body) {
+		c.mu.Lock()
+		// Si hubo un cambio durante la carga, el resultado puede ser viejo
+		if c.generation == generation {
+			c.entries[key] = entry
+		}
+		c.mu.Unlock()
+	}
+	return entry, nil
+}
+
+// Invalidate descarta todas las entradas; la siguiente solicitud de cada una
+// vuelve a consultar la base de datos.
+func (c *Cache) Invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*Entry)
+	c.generation++
+	c.changedAt = time.Now()
+}
+
+func newEntry(value any, changedAt time.Time) (*Entry, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error al serializar el catálogo: %w", err)
+	}
+	// Igual que json.Encoder, que usan el resto de los handlers
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	return &Entry{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: changedAt.UTC().Truncate(time.Second),
+	}, nil
+}
+
+func isEmpty(body []byte) bool {
+	switch string(body) {
+	case "null\n", "[]\n":
+		return true
+	}
+	return false
+}
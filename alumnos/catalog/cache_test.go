@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// counter cuenta las llamadas a load y devuelve value.
+type counter struct {
+	calls int
+	value any
+	err   error
+}
+
+func (c *counter) load(context.Context) (any, error) {
+	c.calls++
+	return c.value, c.err
+}
+
+func TestCacheGet(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("base de datos caída")
+
+	tests := []struct {
+		name      string
+		cache     *Cache
+		value     any
+		err       error
+		wantCalls int // llamadas a load tras dos Get
+		wantErr   error
+	}{
+		{"guarda el resultado", New(), []string{"Ingeniería"}, nil, 1, nil},
+		{"no guarda listas vacías", New(), []string{}, nil, 2, nil},
+		{"no guarda null", New(), nil, nil, 2, nil},
+		{"no guarda errores", New(), nil, errDown, 2, errDown},
+		{"nil no guarda nada", nil, []string{"Ingeniería"}, nil, 2, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load := &counter{value: tt.value, err: tt.err}
+			for i := 0; i < 2; i++ {
+				if _, err := tt.cache.Get(ctx, "courses", load.load); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Get() error = %v, se esperaba %v", err, tt.wantErr)
+				}
+			}
+			if load.calls != tt.wantCalls {
+				t.Errorf("load se llamó %d veces, se esperaban %d", load.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	cache := New()
+	load := &counter{value: []string{"Ingeniería"}}
+
+	first, err := cache.Get(ctx, "courses", load.load)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	cache.Invalidate()
+
+	load.value = []string{"Ingeniería", "Medicina"}
+	second, err := cache.Get(ctx, "courses", load.load)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if load.calls != 2 {
+		t.Errorf("load se llamó %d veces, se esperaban 2", load.calls)
+	}
+	if second.ETag == first.ETag {
+		t.Error("el ETag no cambió con el contenido")
+	}
+	if string(second.Body) != `["Ingeniería","Medicina"]`+"\n" {
+		t.Errorf("Body = %q", second.Body)
+	}
+	if second.LastModified.Before(first.LastModified) {
+		t.Errorf("LastModified retrocedió: %v < %v", second.LastModified, first.LastModified)
+	}
+}
+
+// Un resultado cargado mientras los catálogos cambiaban puede ser viejo y no
+// se guarda.
+func TestCacheInvalidateDuringLoad(t *testing.T) {
+	ctx := context.Background()
+	cache := New()
+
+	calls := 0
+	load := func(context.Context) (any, error) {
+		calls++
+		if calls == 1 {
+			cache.Invalidate()
+		}
+		return []string{"Ingeniería"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Get(ctx, "courses", load); err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("load se llamó %d veces, se esperaban 2", calls)
+	}
+}
+
+func TestNewEntry(t *testing.T) {
+	changedAt := time.Date(2025, 3, 1, 10, 30, 15, 500, time.FixedZone("CST", -6*3600))
+
+	a, err := newEntry([]int{1, 2}, changedAt)
+	if err != nil {
+		t.Fatalf("newEntry: %v", err)
+	}
+	b, err := newEntry([]int{1, 2}, changedAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("newEntry: %v", err)
+	}
+
+	if a.ETag != b.ETag {
+		t.Errorf("el mismo cuerpo dio ETag distintos: %s y %s", a.ETag, b.ETag)
+	}
+	if len(a.ETag) != 34 || a.ETag[0] != '"' || a.ETag[33] != '"' {
+		t.Errorf("ETag = %s, se esperaba un ETag fuerte entre comillas", a.ETag)
+	}
+	if want := time.Date(2025, 3, 1, 16, 30, 15, 0, time.UTC); !a.LastModified.Equal(want) || a.LastModified.Location() != time.UTC {
+		t.Errorf("LastModified = %v, se esperaba %v", a.LastModified, want)
+	}
+	if _, err := newEntry(make(chan int), changedAt); err == nil {
+		t.Error("newEntry aceptó un valor que no se puede serializar")
+	}
+}
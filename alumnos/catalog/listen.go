@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// channel es el canal de NOTIFY que usan los triggers de
+// db/migrations/0011_catalog_notify.sql.
+const channel = "catalog_changed"
+
+// retryDelay es la espera antes de volver a escuchar tras perder la conexión.
+const retryDelay = 5 * time.Second
+
+// Listen mantiene una conexión con LISTEN catalog_changed e invalida la caché
+// con cada aviso, hasta que ctx se cancela. Si la conexión se pierde también
+// invalida, porque los avisos de ese intervalo no llegan.
+func (c *Cache) Listen(ctx context.Context, pool *pgxpool.Pool, logger *slog.Logger) {
+	for {
+		err := c.listen(ctx, pool, logger)
+		if ctx.Err() != nil {
+			return
+		}
+		c.Invalidate()
+		logger.Warn("se perdió el aviso de cambios de catálogos; reintentando",
+			"retry_in", retryDelay.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+func (c *Cache) listen(ctx context.Context, pool *pgxpool.Pool, logger *slog.Logger) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("error al obtener una conexión: %w", err)
+	}
+	// La conexión queda en LISTEN; sale del pool y se cierra al terminar
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+channel); err != nil {
+		return fmt.Errorf("error al escuchar %s: %w", channel, err)
+	}
+	// Lo cargado antes del LISTEN pudo cambiar sin aviso
+	c.Invalidate()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("error al esperar avisos: %w", err)
+		}
+		c.Invalidate()
+		logger.Info("catálogo modificado; caché invalidada", "table", notification.Payload)
+	}
+}
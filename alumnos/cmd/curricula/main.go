@@ -0,0 +1,65 @@
+package main
+
+import (
+	"alumnos/config"
+	"alumnos/curriculum"
+	postgres "alumnos/db"
+	"alumnos/repository"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Aplica los planes de estudio sin reiniciar el servidor.
+// Uso: go run ./cmd/curricula -dir ./curriculum/data [-dsn ...]
+// Sin -dsn usa la misma conexión que el servidor.
+func main() {
+	dsn := flag.String("dsn", "", "cadena de conexión a PostgreSQL; por defecto, la del servidor (CONFIG_FILE, DATABASE_URL o PG*)")
+	dir := flag.String("dir", "", "directorio con los archivos *.json (por defecto, los incluidos en el binario)")
+	flag.Parse()
+
+	curricula, err := curriculum.FromDir(*dir)
+	if err != nil {
+		fmt.Printf("Error al cargar planes de estudio: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadDatabase(*dsn, os.Getenv)
+	if err != nil {
+		fmt.Printf("Error en la configuración: %v\n", err)
+		os.Exit(1)
+	}
+	poolConfig, err := cfg.PoolConfig()
+	if err != nil {
+		fmt.Printf("Error en la configuración: %v\n", err)
+		os.Exit(1)
+	}
+
+	dbPool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		fmt.Printf("Error al conectar a la base de datos: %v\n", err)
+		os.Exit(1)
+	}
+	defer dbPool.Close()
+
+	// Las restricciones únicas que usa el upsert llegan con las migraciones
+	if _, err := postgres.Migrate(context.Background(), dbPool); err != nil {
+		fmt.Printf("Error al aplicar migraciones: %v\n", err)
+		os.Exit(1)
+	}
+
+	repo := repository.NewPgxStorage(dbPool, slog.Default())
+	for _, c := range curricula {
+		result, err := repo.ApplyCurriculum(context.Background(), c)
+		if err != nil {
+			fmt.Printf("Error al aplicar plan de estudios: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Plan de estudios %s (%s): %d nuevas, %d actualizadas, %d dadas de baja, %d sin cambios, %d modificadas por un administrador\n",
+			result.Course, result.Version, result.Inserted, result.Updated, result.Retired, result.Unchanged, result.Skipped)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"alumnos/config"
+	"alumnos/models"
+	"alumnos/repository"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Une las inscripciones repetidas de semester_course (mismo alumno, semestre
+// y materia) para que la migración 0013 pueda agregar la restricción única.
+// Sin -apply solo las lista. Sin -dsn usa la misma conexión que el servidor.
+// Los grupos con parciales inválidos no se unen; se listan y el programa
+// termina con código 2 para que se corrijan a mano antes de actualizar.
+// Uso: go run ./cmd/dedupe-enrollments [-dsn ...] [-apply]
+func main() {
+	dsn := flag.String("dsn", "", "cadena de conexión a PostgreSQL; por defecto, la del servidor (CONFIG_FILE, DATABASE_URL o PG*)")
+	apply := flag.Bool("apply", false, "unir las inscripciones repetidas; sin este flag solo se listan")
+	flag.Parse()
+
+	cfg, err := config.LoadDatabase(*dsn, os.Getenv)
+	if err != nil {
+		fmt.Printf("Error en la configuración: %v\n", err)
+		os.Exit(1)
+	}
+	poolConfig, err := cfg.PoolConfig()
+	if err != nil {
+		fmt.Printf("Error en la configuración: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	dbPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		fmt.Printf("Error al conectar a la base de datos: %v\n", err)
+		os.Exit(1)
+	}
+	defer dbPool.Close()
+
+	// Sin aplicar migraciones: la 0013 falla mientras existan las copias
+	repo := repository.NewPgxStorage(dbPool, slog.Default())
+
+	var merged, skipped []models.DuplicateEnrollment
+	if *apply {
+		merged, skipped, err = repo.MergeDuplicateEnrollments(ctx)
+	} else {
+		var duplicates []models.DuplicateEnrollment
+		duplicates, err = repo.FindDuplicateEnrollments(ctx)
+		for _, d := range duplicates {
+			if d.NeedsManualFix() {
+				skipped = append(skipped, d)
+			} else {
+				merged = append(merged, d)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(merged) == 0 && len(skipped) == 0 {
+		fmt.Println("No hay inscripciones repetidas")
+		return
+	}
+
+	conflicts := 0
+	for _, d := range merged {
+		fmt.Printf("Alumno %d, semestre %d, %s (%s): se conserva %d, se unen %v",
+			d.AlumnID, d.SemesterID, d.SubjectName, d.SubjectKey, d.KeptID, d.MergedIDs)
+		if d.GradeConflicts > 0 {
+			// Se conserva la calificación modificada más recientemente
+			fmt.Printf(" (%d parciales con calificaciones distintas)", d.GradeConflicts)
+			conflicts++
+		}
+		fmt.Println()
+	}
+	if *apply {
+		fmt.Printf("%d inscripciones repetidas unidas; %d con calificaciones distintas quedaron con la más reciente\n", len(merged), conflicts)
+	} else {
+		fmt.Printf("%d inscripciones repetidas se pueden unir (%d con calificaciones distintas); use -apply para unirlas\n", len(merged), conflicts)
+	}
+
+	if len(skipped) == 0 {
+		return
+	}
+	// Unir estos grupos cambiaría o descartaría calificaciones: se corrigen a
+	// mano y se vuelve a ejecutar. Mientras tanto la migración 0013 sigue fallando.
+	fmt.Printf("\n%d grupos requieren corrección manual y no se unen:\n", len(skipped))
+	for _, d := range skipped {
+		fmt.Printf("Alumno %d, semestre %d, %s (%s): inscripciones %d y %v",
+			d.AlumnID, d.SemesterID, d.SubjectName, d.SubjectKey, d.KeptID, d.MergedIDs)
+		if d.InvalidGrades > 0 {
+			fmt.Printf(" (%d calificaciones fuera de 0 a 10)", d.InvalidGrades)
+		}
+		if d.InvalidPartials > 0 {
+			fmt.Printf(" (%d parciales distintos de 1 y 2)", d.InvalidPartials)
+		}
+		fmt.Println()
+	}
+	os.Exit(2)
+}
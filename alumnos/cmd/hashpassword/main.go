@@ -0,0 +1,24 @@
+package main
+
+import (
+	"alumnos/auth"
+	"fmt"
+	"os"
+)
+
+// Genera el hash bcrypt que se guarda en teacher.password.
+// Uso: go run ./cmd/hashpassword <contraseña>
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Println("Uso: hashpassword <contraseña>")
+		os.Exit(1)
+	}
+
+	hash, err := auth.HashPassword(os.Args[1])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(hash)
+}
@@ -2,60 +2,254 @@ package main
 
 import (
 	"alumnos/api"
+	"alumnos/auth"
+	"alumnos/catalog"
+	"alumnos/config"
+	"alumnos/curriculum"
+	postgres "alumnos/db"
+	"alumnos/logging"
+	"alumnos/metrics"
+	"alumnos/ratelimit"
 	"alumnos/repository"
+	"alumnos/tracing"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
-	// DSN directo en el código
-	dsn := "postgresql://root:root@db:5432/alumnos?sslmode=disable"
+	// Configuración desde archivo, entorno y flags; los errores no incluyen secretos
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Validate ya comprobó el nivel de log
+	level, _ := logging.ParseLevel(cfg.LogLevel)
+	logger := logging.New(os.Stdout, level)
+	slog.SetDefault(logger)
+	logger.Info("configuración cargada", "config", cfg)
+
+	// SIGTERM (docker stop) y SIGINT inician el apagado ordenado
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg, logger); err != nil {
+		logger.Error("el servidor terminó con error", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("servidor detenido")
+}
+
+// purgeIdempotencyKeys borra cada hora las claves de idempotencia vencidas.
+func purgeIdempotencyKeys(ctx context.Context, repo *repository.PgxStorage, logger *slog.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := repo.PurgeIdempotencyKeys(ctx, api.IdempotencyTTL)
+			if err != nil {
+				logger.Warn("no se pudieron borrar las claves de idempotencia vencidas", "error", err)
+				continue
+			}
+			if purged > 0 {
+				logger.Info("claves de idempotencia vencidas borradas", "count", purged)
+			}
+		}
+	}
+}
+
+// run prepara la base de datos y atiende solicitudes hasta que ctx se cancela.
+// Al volver, el servidor ya terminó las solicitudes en curso y el pool está cerrado.
+func run(ctx context.Context, cfg *config.Config, logger *slog.Logger) error {
+	// Trazas: se configuran primero y se vacían al final, después de cerrar el pool
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Warn("no se pudieron exportar las últimas trazas", "error", err)
+		}
+	}()
 
 	// Conexión a la base de datos
-	dbPool, err := pgxpool.New(context.Background(), dsn)
+	poolConfig, err := cfg.PoolConfig()
 	if err != nil {
-		fmt.Printf("Error al conectar a la base de datos: %v\n", err)
-		return
+		return err
+	}
+	// Cada consulta pasa por el log (nivel debug), las métricas y las trazas
+	appMetrics := metrics.New()
+	poolConfig.ConnConfig.Tracer = multitracer.New(
+		&repository.QueryLogger{Logger: logger},
+		appMetrics.QueryTracer(),
+		tracing.QueryTracer{},
+	)
+
+	dbPool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return fmt.Errorf("error al conectar a la base de datos: %w", err)
+	}
+	appMetrics.RegisterPool(dbPool)
+	// Se cierra al final, cuando ya no quedan solicitudes usando conexiones
+	defer func() {
+		dbPool.Close()
+		logger.Info("pool de conexiones cerrado")
+	}()
+
+	// Esperar a que la base de datos responda antes de migrar; reemplaza a wait-for-it.sh
+	err = postgres.WaitForDatabase(ctx, dbPool, postgres.Backoff{
+		Attempts: cfg.Database.ConnectAttempts,
+		Initial:  cfg.Database.ConnectInitialBackoff,
+		Max:      cfg.Database.ConnectMaxBackoff,
+	}, logger)
+	if err != nil {
+		return err
 	}
-	defer dbPool.Close()
 
-	fmt.Println("Conexión a la base de datos exitosa")
+	// Si la base de datos cae después, el servidor sigue en modo degradado
+	monitor := postgres.NewMonitor(dbPool, cfg.Database.HealthInterval, logger)
+	go monitor.Run(ctx)
+
+	// Aplicar migraciones pendientes antes de tocar los catálogos
+	if cfg.Seeds.Migrate {
+		applied, err := postgres.Migrate(repository.WithOperation(ctx, "db.Migrate"), dbPool)
+		if err != nil {
+			return fmt.Errorf("error al aplicar migraciones: %w", err)
+		}
+		for _, name := range applied {
+			logger.Info("migración aplicada", "migration", name)
+		}
+	}
 
 	// Inicializar repositorio y API
-	repo := repository.NewPgxStorage(dbPool)
-	apiInstance := api.NewAPI(repo)
+	repo := repository.NewPgxStorage(dbPool, logger)
+	authManager := auth.NewManager(cfg.Auth.Secret, cfg.Auth.TokenTTL)
+	// Las respuestas idempotentes se repiten durante un día; después se borran
+	go purgeIdempotencyKeys(ctx, repo, logger)
+
+	// Token buckets por usuario (dentro de RequireAuth) y por IP (antes del enrutador)
+	userLimiter := ratelimit.New(cfg.RateLimit.UserRate, cfg.RateLimit.UserBurst)
+	ipLimiter := ratelimit.New(cfg.RateLimit.IPRate, cfg.RateLimit.IPBurst)
+	go userLimiter.Run(ctx, time.Minute)
+	go ipLimiter.Run(ctx, time.Minute)
+	proxies, err := ratelimit.ParseTrustedProxies(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	// Caché de catálogos; los triggers de la base avisan cuando cambian
+	catalogCache := catalog.New()
+	go catalogCache.Listen(repository.WithOperation(ctx, "catalog.Listen"), dbPool, logger)
+	apiInstance := api.NewAPI(repo, authManager, api.Options{
+		Metrics:      appMetrics,
+		UserLimiter:  userLimiter,
+		Catalog:      catalogCache,
+		MaxBodyBytes: cfg.HTTP.MaxBodyBytes,
+	})
 
 	// Configurar enrutador
 	mux := http.NewServeMux()
 	api.RegisterRoutes(mux, apiInstance)
 
-	// Ejecutar seed
-	if err := repo.SeedCatCourses(context.Background()); err != nil {
-		fmt.Printf("Error al ejecutar el seed: %v\n", err)
-		return
+	// Cargar los planes de estudio; curricula-dir permite usar archivos fuera del binario
+	if cfg.Seeds.Curricula {
+		curricula, err := curriculum.FromDir(cfg.Seeds.CurriculaDir)
+		if err != nil {
+			return fmt.Errorf("error al cargar planes de estudio: %w", err)
+		}
+		for _, c := range curricula {
+			result, err := repo.ApplyCurriculum(ctx, c)
+			if err != nil {
+				return fmt.Errorf("error al aplicar plan de estudios: %w", err)
+			}
+			logger.Info("plan de estudios aplicado",
+				"course", result.Course, "version", result.Version,
+				"inserted", result.Inserted, "updated", result.Updated,
+				"retired", result.Retired, "unchanged", result.Unchanged,
+				"skipped", result.Skipped)
+		}
 	}
-	fmt.Println("Seed ejecutado exitosamente")
 
-	// Ejecutar el seed
-	if err := repo.SeedAcademycHistory(context.Background()); err != nil {
-		fmt.Printf("Error al ejecutar el seed: %v\n", err)
-		return
+	if cfg.Seeds.Semesters {
+		seed, err := repo.SeedCatSemesters(ctx)
+		if err != nil {
+			return fmt.Errorf("error al ejecutar el seed: %w", err)
+		}
+		logger.Info("seed aplicado",
+			"table", seed.Table, "inserted", seed.Inserted,
+			"updated", seed.Updated, "unchanged", seed.Unchanged)
 	}
-	fmt.Println("Seed de academyc_history ejecutado exitosamente.")
 
-	if err := repo.SeedCatSemesters(context.Background()); err != nil {
-		fmt.Printf("Error al ejecutar el seed: %v\n", err)
-		return
+	// Middleware de adentro hacia afuera: límites por IP y de tamaño, CORS, log
+	// y métricas, request ID y traza. Las respuestas 429 y 413 también se
+	// registran y llevan los headers de CORS.
+	handler := api.LimitRequests(ipLimiter, proxies, cfg.HTTP.MaxBodyBytes, mux)
+	handler = api.CORS(api.CORSPolicy{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}, mux, handler)
+	handler = api.LogRequests(logger, handler, appMetrics.ObserveRequest, tracing.ObserveRequest)
+	handler = tracing.Middleware(api.RequestID(handler), proxies.FromProxy)
+
+	// Servidor con límites de tiempo para que un cliente lento no retenga conexiones.
+	// Las solicitudes no heredan ctx: la señal no debe cancelar las que están en curso.
+	server := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTP.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
 	}
-	fmt.Println("Seed de cat_semesters ejecutado exitosamente.")
 
-	// Iniciar servidor
-	port := "8080"
-	fmt.Printf("Servidor escuchando en :%s\n", port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		fmt.Printf("Error al iniciar el servidor: %v\n", err)
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("servidor escuchando", "addr", cfg.HTTP.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		// No se pudo abrir el puerto o el servidor falló antes de recibir la señal
+		return fmt.Errorf("error al iniciar el servidor: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Dejar de aceptar conexiones y esperar a las solicitudes en curso
+	logger.Info("apagando el servidor", "timeout", cfg.HTTP.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// Vencido el plazo se cierran las conexiones restantes; sus contextos se
+		// cancelan y las transacciones abiertas se revierten en lugar de quedar a medias
+		server.Close()
+		return fmt.Errorf("no todas las solicitudes terminaron a tiempo: %w", err)
 	}
+	return nil
 }
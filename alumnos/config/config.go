@@ -0,0 +1,653 @@
+// Package config reúne la configuración del servidor. Cada opción se puede
+// fijar en un archivo JSON, en una variable de entorno o con un flag; si se
+// indica en varios lugares, el flag gana al entorno y el entorno al archivo.
+package config
+
+import (
+	"alumnos/logging"
+	"alumnos/ratelimit"
+	"alumnos/tracing"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Config struct {
+	Database  DatabaseConfig
+	HTTP      HTTPConfig
+	RateLimit RateLimitConfig
+	CORS      CORSConfig
+	Seeds     SeedConfig
+	Auth      AuthConfig
+	Tracing   tracing.Config
+	LogLevel  string
+}
+
+// DatabaseConfig acepta una cadena de conexión completa (URL) o los campos
+// por separado; si URL no está vacía, los campos se ignoran.
+type DatabaseConfig struct {
+	URL      string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+
+	MaxConns        int
+	MinConns        int
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+
+	// Espera inicial de la base de datos y monitoreo posterior
+	ConnectAttempts       int // 0 reintenta sin límite
+	ConnectInitialBackoff time.Duration
+	ConnectMaxBackoff     time.Duration
+	HealthInterval        time.Duration
+}
+
+type HTTPConfig struct {
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	MaxBodyBytes      int64
+	ShutdownTimeout   time.Duration // plazo para terminar las solicitudes en curso
+}
+
+// RateLimitConfig define los token buckets por IP y por usuario: Rate fichas
+// por segundo con ráfagas de hasta Burst solicitudes. Rate 0 desactiva el límite.
+type RateLimitConfig struct {
+	IPRate         float64
+	IPBurst        int
+	UserRate       float64
+	UserBurst      int
+	TrustedProxies string // CIDR separados por comas cuyo X-Forwarded-For y traceparent se aceptan
+}
+
+// CORSConfig define qué orígenes del navegador pueden llamar a la API. Sin
+// orígenes, CORS queda desactivado y solo funciona el frontend del mismo host.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// SeedConfig controla las tareas que modifican la base de datos al arrancar.
+type SeedConfig struct {
+	Migrate      bool
+	Curricula    bool
+	Semesters    bool
+	CurriculaDir string // vacío usa los planes incluidos en el binario
+}
+
+type AuthConfig struct {
+	Secret   string
+	TokenTTL time.Duration
+}
+
+// Default devuelve los valores usados cuando una opción no se configura.
+func Default() Config {
+	return Config{
+		Database: DatabaseConfig{
+			Host:            "db",
+			Port:            5432,
+			User:            "root",
+			Name:            "alumnos",
+			SSLMode:         "disable",
+			MaxConns:        10,
+			MinConns:        0,
+			MaxConnLifetime: time.Hour,
+			MaxConnIdleTime: 30 * time.Minute,
+
+			ConnectAttempts:       10,
+			ConnectInitialBackoff: 500 * time.Millisecond,
+			ConnectMaxBackoff:     30 * time.Second,
+			HealthInterval:        10 * time.Second,
+		},
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			MaxBodyBytes:      1 << 20,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			IPRate:    20,
+			IPBurst:   40,
+			UserRate:  10,
+			UserBurst: 20,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "X-Request-ID", "Idempotency-Key"},
+			MaxAge:         10 * time.Minute,
+		},
+		Seeds: SeedConfig{
+			Migrate:   true,
+			Curricula: true,
+			Semesters: true,
+		},
+		Auth: AuthConfig{
+			TokenTTL: 12 * time.Hour,
+		},
+		Tracing: tracing.Config{
+			Exporter:     tracing.ExporterNone,
+			OTLPEndpoint: "http://localhost:4318",
+			SampleRatio:  1,
+		},
+		LogLevel: "info",
+	}
+}
+
+// envNames asocia cada flag con su variable de entorno. Las claves del
+// archivo de configuración son los mismos nombres de los flags.
+var envNames = map[string]string{
+	"database-url":             "DATABASE_URL",
+	"pg-host":                  "PGHOST",
+	"pg-port":                  "PGPORT",
+	"pg-user":                  "PGUSER",
+	"pg-password":              "PGPASSWORD",
+	"pg-database":              "PGDATABASE",
+	"pg-sslmode":               "PGSSLMODE",
+	"db-max-conns":             "DB_MAX_CONNS",
+	"db-min-conns":             "DB_MIN_CONNS",
+	"db-max-conn-lifetime":     "DB_MAX_CONN_LIFETIME",
+	"db-max-conn-idle-time":    "DB_MAX_CONN_IDLE_TIME",
+	"db-connect-attempts":      "DB_CONNECT_ATTEMPTS",
+	"db-connect-backoff":       "DB_CONNECT_BACKOFF",
+	"db-connect-max-backoff":   "DB_CONNECT_MAX_BACKOFF",
+	"db-health-interval":       "DB_HEALTH_INTERVAL",
+	"http-addr":                "HTTP_ADDR",
+	"http-read-header-timeout": "HTTP_READ_HEADER_TIMEOUT",
+	"http-read-timeout":        "HTTP_READ_TIMEOUT",
+	"http-write-timeout":       "HTTP_WRITE_TIMEOUT",
+	"http-idle-timeout":        "HTTP_IDLE_TIMEOUT",
+	"http-max-header-bytes":    "HTTP_MAX_HEADER_BYTES",
+	"http-max-body-bytes":      "HTTP_MAX_BODY_BYTES",
+	"http-shutdown-timeout":    "HTTP_SHUTDOWN_TIMEOUT",
+	"rate-limit-ip-rps":        "RATE_LIMIT_IP_RPS",
+	"rate-limit-ip-burst":      "RATE_LIMIT_IP_BURST",
+	"rate-limit-user-rps":      "RATE_LIMIT_USER_RPS",
+	"rate-limit-user-burst":    "RATE_LIMIT_USER_BURST",
+	"trusted-proxies":          "TRUSTED_PROXIES",
+	"cors-allowed-origins":     "CORS_ALLOWED_ORIGINS",
+	"cors-allowed-methods":     "CORS_ALLOWED_METHODS",
+	"cors-allowed-headers":     "CORS_ALLOWED_HEADERS",
+	"cors-allow-credentials":   "CORS_ALLOW_CREDENTIALS",
+	"cors-max-age":             "CORS_MAX_AGE",
+	"migrate":                  "RUN_MIGRATIONS",
+	"seed-curricula":           "SEED_CURRICULA",
+	"seed-semesters":           "SEED_SEMESTERS",
+	"curricula-dir":            "CURRICULA_DIR",
+	"auth-secret":              "AUTH_SECRET",
+	"auth-token-ttl":           "AUTH_TOKEN_TTL",
+	"tracing-exporter":         "TRACING_EXPORTER",
+	"tracing-otlp-endpoint":    "OTEL_EXPORTER_OTLP_ENDPOINT",
+	"tracing-sample-ratio":     "TRACING_SAMPLE_RATIO",
+	"log-level":                "LOG_LEVEL",
+}
+
+// secretFlags nunca se muestran: ni en -help, ni en los logs, ni en errores.
+var secretFlags = map[string]bool{
+	"database-url": true,
+	"pg-password":  true,
+	"auth-secret":  true,
+}
+
+func (c *Config) flagSet(configPath *string) *flag.FlagSet {
+	fs := flag.NewFlagSet("alumnos", flag.ContinueOnError)
+	fs.StringVar(configPath, "config", *configPath, "archivo JSON de configuración (CONFIG_FILE)")
+
+	fs.StringVar(&c.Database.URL, "database-url", c.Database.URL, "cadena de conexión completa; reemplaza a los campos pg-*")
+	fs.StringVar(&c.Database.Host, "pg-host", c.Database.Host, "host de PostgreSQL")
+	fs.IntVar(&c.Database.Port, "pg-port", c.Database.Port, "puerto de PostgreSQL")
+	fs.StringVar(&c.Database.User, "pg-user", c.Database.User, "usuario de PostgreSQL")
+	fs.StringVar(&c.Database.Password, "pg-password", c.Database.Password, "contraseña de PostgreSQL")
+	fs.StringVar(&c.Database.Name, "pg-database", c.Database.Name, "nombre de la base de datos")
+	fs.StringVar(&c.Database.SSLMode, "pg-sslmode", c.Database.SSLMode, "sslmode de la conexión")
+	fs.IntVar(&c.Database.MaxConns, "db-max-conns", c.Database.MaxConns, "máximo de conexiones del pool")
+	fs.IntVar(&c.Database.MinConns, "db-min-conns", c.Database.MinConns, "conexiones que el pool mantiene abiertas")
+	fs.DurationVar(&c.Database.MaxConnLifetime, "db-max-conn-lifetime", c.Database.MaxConnLifetime, "vida máxima de una conexión")
+	fs.DurationVar(&c.Database.MaxConnIdleTime, "db-max-conn-idle-time", c.Database.MaxConnIdleTime, "tiempo máximo de una conexión inactiva")
+	fs.IntVar(&c.Database.ConnectAttempts, "db-connect-attempts", c.Database.ConnectAttempts, "intentos para conectar al arrancar; 0 sin límite")
+	fs.DurationVar(&c.Database.ConnectInitialBackoff, "db-connect-backoff", c.Database.ConnectInitialBackoff, "espera tras el primer intento fallido; se duplica en cada intento")
+	fs.DurationVar(&c.Database.ConnectMaxBackoff, "db-connect-max-backoff", c.Database.ConnectMaxBackoff, "espera máxima entre intentos de conexión")
+	fs.DurationVar(&c.Database.HealthInterval, "db-health-interval", c.Database.HealthInterval, "cada cuánto se comprueba la base de datos en ejecución")
+
+	fs.StringVar(&c.HTTP.Addr, "http-addr", c.HTTP.Addr, "dirección de escucha host:puerto")
+	fs.DurationVar(&c.HTTP.ReadHeaderTimeout, "http-read-header-timeout", c.HTTP.ReadHeaderTimeout, "tiempo máximo para leer los headers")
+	fs.DurationVar(&c.HTTP.ReadTimeout, "http-read-timeout", c.HTTP.ReadTimeout, "tiempo máximo para leer la solicitud completa")
+	fs.DurationVar(&c.HTTP.WriteTimeout, "http-write-timeout", c.HTTP.WriteTimeout, "tiempo máximo para escribir la respuesta")
+	fs.DurationVar(&c.HTTP.IdleTimeout, "http-idle-timeout", c.HTTP.IdleTimeout, "tiempo máximo de una conexión keep-alive inactiva")
+	fs.IntVar(&c.HTTP.MaxHeaderBytes, "http-max-header-bytes", c.HTTP.MaxHeaderBytes, "tamaño máximo de los headers de una solicitud")
+	fs.Int64Var(&c.HTTP.MaxBodyBytes, "http-max-body-bytes", c.HTTP.MaxBodyBytes, "tamaño máximo del cuerpo de una solicitud")
+	fs.DurationVar(&c.HTTP.ShutdownTimeout, "http-shutdown-timeout", c.HTTP.ShutdownTimeout, "plazo para terminar las solicitudes en curso al apagar")
+
+	fs.Float64Var(&c.RateLimit.IPRate, "rate-limit-ip-rps", c.RateLimit.IPRate, "solicitudes por segundo por IP de cliente; 0 sin límite")
+	fs.IntVar(&c.RateLimit.IPBurst, "rate-limit-ip-burst", c.RateLimit.IPBurst, "ráfaga máxima por IP de cliente")
+	fs.Float64Var(&c.RateLimit.UserRate, "rate-limit-user-rps", c.RateLimit.UserRate, "solicitudes por segundo por usuario autenticado; 0 sin límite")
+	fs.IntVar(&c.RateLimit.UserBurst, "rate-limit-user-burst", c.RateLimit.UserBurst, "ráfaga máxima por usuario autenticado")
+	fs.StringVar(&c.RateLimit.TrustedProxies, "trusted-proxies", c.RateLimit.TrustedProxies, "redes CIDR de los proxies cuyo X-Forwarded-For y traceparent se aceptan, separadas por comas")
+
+	fs.Var((*listValue)(&c.CORS.AllowedOrigins), "cors-allowed-origins", "orígenes permitidos separados por comas, p. ej. https://app.ax01.dev; vacío desactiva CORS")
+	fs.Var((*listValue)(&c.CORS.AllowedMethods), "cors-allowed-methods", "métodos permitidos en solicitudes CORS")
+	fs.Var((*listValue)(&c.CORS.AllowedHeaders), "cors-allowed-headers", "headers que el navegador puede enviar")
+	fs.BoolVar(&c.CORS.AllowCredentials, "cors-allow-credentials", c.CORS.AllowCredentials, "permitir credenciales (cookies, Authorization) en solicitudes CORS")
+	fs.DurationVar(&c.CORS.MaxAge, "cors-max-age", c.CORS.MaxAge, "cuánto guarda el navegador la respuesta a un preflight")
+
+	fs.BoolVar(&c.Seeds.Migrate, "migrate", c.Seeds.Migrate, "aplicar las migraciones pendientes al arrancar")
+	fs.BoolVar(&c.Seeds.Curricula, "seed-curricula", c.Seeds.Curricula, "aplicar los planes de estudio al arrancar")
+	fs.BoolVar(&c.Seeds.Semesters, "seed-semesters", c.Seeds.Semesters, "cargar el catálogo de semestres al arrancar")
+	fs.StringVar(&c.Seeds.CurriculaDir, "curricula-dir", c.Seeds.CurriculaDir, "directorio con planes de estudio *.json (por defecto, los incluidos)")
+
+	fs.StringVar(&c.Auth.Secret, "auth-secret", c.Auth.Secret, "secreto para firmar los tokens de sesión")
+	fs.DurationVar(&c.Auth.TokenTTL, "auth-token-ttl", c.Auth.TokenTTL, "vigencia de los tokens de sesión")
+
+	fs.StringVar(&c.Tracing.Exporter, "tracing-exporter", c.Tracing.Exporter, "exportador de trazas: none, stdout u otlp")
+	fs.StringVar(&c.Tracing.OTLPEndpoint, "tracing-otlp-endpoint", c.Tracing.OTLPEndpoint, "URL del colector OTLP/HTTP")
+	fs.Float64Var(&c.Tracing.SampleRatio, "tracing-sample-ratio", c.Tracing.SampleRatio, "fracción de trazas que se muestrean, entre 0 y 1")
+
+	fs.StringVar(&c.LogLevel, "log-level", c.LogLevel, "nivel de log: debug, info, warn o error")
+
+	// Mostrar la variable de entorno en -help y ocultar los valores secretos
+	fs.VisitAll(func(f *flag.Flag) {
+		if env, ok := envNames[f.Name]; ok {
+			f.Usage += " (" + env + ")"
+		}
+		if secretFlags[f.Name] {
+			f.DefValue = ""
+		}
+	})
+	return fs
+}
+
+// Load arma la configuración a partir de los valores por defecto, el archivo
+// indicado con -config o CONFIG_FILE, las variables de entorno y los flags,
+// en ese orden de prioridad creciente, y la valida.
+func Load(args []string, getenv func(string) string) (*Config, error) {
+	cfg, err := parse(args, getenv)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadDatabase lee la conexión a la base de datos para los comandos de
+// mantenimiento (cmd/curricula, cmd/dedupe-enrollments) del mismo archivo y
+// las mismas variables de entorno que el servidor, sin exigir el resto de la
+// configuración (AUTH_SECRET...). databaseURL, si no está vacía, reemplaza a
+// DATABASE_URL y a los campos PG* igual que el flag -database-url.
+func LoadDatabase(databaseURL string, getenv func(string) string) (*Config, error) {
+	cfg, err := parse(nil, getenv)
+	if err != nil {
+		return nil, err
+	}
+	if databaseURL != "" {
+		cfg.Database.URL = databaseURL
+	}
+	if errs := cfg.Database.validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("configuración inválida:\n%w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// parse arma la configuración sin validarla.
+func parse(args []string, getenv func(string) string) (*Config, error) {
+	// Primera pasada solo para conocer la ruta del archivo
+	configPath := getenv("CONFIG_FILE")
+	scratch := Default()
+	if err := scratch.flagSet(&configPath).Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	var ignored string
+	fs := cfg.flagSet(&ignored)
+
+	if configPath != "" {
+		if err := loadFile(fs, configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(envNames))
+	for name := range envNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := getenv(envNames[name])
+		if value == "" {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return nil, settingError(envNames[name], name, err)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("argumentos no reconocidos: %s", strings.Join(fs.Args(), " "))
+	}
+	return &cfg, nil
+}
+
+// loadFile aplica un objeto JSON plano cuyas claves son los nombres de los
+// flags, p. ej. {"http-addr": ":9090", "db-max-conns": 20}. Las duraciones
+// se escriben como texto ("30s").
+func loadFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error al leer el archivo de configuración: %w", err)
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("error al leer el archivo de configuración %s: %w", path, err)
+	}
+
+	for name, raw := range values {
+		if _, ok := envNames[name]; !ok {
+			return fmt.Errorf("el archivo de configuración %s tiene la opción desconocida %q", path, name)
+		}
+
+		var value string
+		switch v := raw.(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			value = strconv.FormatBool(v)
+		case []any:
+			// Las listas (cors-allowed-origins...) también se aceptan como arreglos
+			items := make([]string, len(v))
+			for i, item := range v {
+				text, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("el archivo de configuración %s: %q debe ser una lista de textos", path, name)
+				}
+				items[i] = text
+			}
+			value = strings.Join(items, ",")
+		default:
+			return fmt.Errorf("el archivo de configuración %s: %q debe ser texto, número, booleano o lista", path, name)
+		}
+
+		if err := fs.Set(name, value); err != nil {
+			return settingError(path, name, err)
+		}
+	}
+	return nil
+}
+
+// settingError describe un valor inválido sin repetirlo si es secreto.
+func settingError(source, name string, err error) error {
+	if secretFlags[name] {
+		return fmt.Errorf("%s: valor inválido para %s", source, name)
+	}
+	return fmt.Errorf("%s: valor inválido para %s: %w", source, name, err)
+}
+
+// Validate revisa la configuración completa y reporta todos los problemas juntos.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	errs = append(errs, c.Database.validate()...)
+	check(c.Database.MaxConns >= 1, "db-max-conns (DB_MAX_CONNS) debe ser al menos 1")
+	check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns,
+		"db-min-conns (DB_MIN_CONNS) debe estar entre 0 y db-max-conns")
+	check(c.Database.MaxConnLifetime > 0, "db-max-conn-lifetime (DB_MAX_CONN_LIFETIME) debe ser positivo")
+	check(c.Database.MaxConnIdleTime > 0, "db-max-conn-idle-time (DB_MAX_CONN_IDLE_TIME) debe ser positivo")
+	check(c.Database.ConnectAttempts >= 0, "db-connect-attempts (DB_CONNECT_ATTEMPTS) no puede ser negativo")
+	check(c.Database.ConnectInitialBackoff > 0 && c.Database.ConnectInitialBackoff <= c.Database.ConnectMaxBackoff,
+		"db-connect-backoff (DB_CONNECT_BACKOFF) debe ser positivo y no mayor que db-connect-max-backoff")
+	check(c.Database.HealthInterval > 0, "db-health-interval (DB_HEALTH_INTERVAL) debe ser positivo")
+
+	if _, port, err := net.SplitHostPort(c.HTTP.Addr); err != nil || port == "" {
+		errs = append(errs, fmt.Errorf("http-addr (HTTP_ADDR) debe tener la forma host:puerto, p. ej. :8080"))
+	}
+	check(c.HTTP.ReadHeaderTimeout > 0, "http-read-header-timeout (HTTP_READ_HEADER_TIMEOUT) debe ser positivo")
+	check(c.HTTP.ReadTimeout > 0, "http-read-timeout (HTTP_READ_TIMEOUT) debe ser positivo")
+	check(c.HTTP.WriteTimeout > 0, "http-write-timeout (HTTP_WRITE_TIMEOUT) debe ser positivo")
+	check(c.HTTP.IdleTimeout > 0, "http-idle-timeout (HTTP_IDLE_TIMEOUT) debe ser positivo")
+	check(c.HTTP.MaxHeaderBytes >= 4<<10, "http-max-header-bytes (HTTP_MAX_HEADER_BYTES) debe ser al menos 4096")
+	check(c.HTTP.MaxBodyBytes >= 1<<10, "http-max-body-bytes (HTTP_MAX_BODY_BYTES) debe ser al menos 1024")
+	check(c.HTTP.ShutdownTimeout > 0, "http-shutdown-timeout (HTTP_SHUTDOWN_TIMEOUT) debe ser positivo")
+
+	check(c.RateLimit.IPRate >= 0, "rate-limit-ip-rps (RATE_LIMIT_IP_RPS) no puede ser negativo")
+	check(c.RateLimit.IPRate == 0 || c.RateLimit.IPBurst >= 1, "rate-limit-ip-burst (RATE_LIMIT_IP_BURST) debe ser al menos 1")
+	check(c.RateLimit.UserRate >= 0, "rate-limit-user-rps (RATE_LIMIT_USER_RPS) no puede ser negativo")
+	check(c.RateLimit.UserRate == 0 || c.RateLimit.UserBurst >= 1, "rate-limit-user-burst (RATE_LIMIT_USER_BURST) debe ser al menos 1")
+	if _, err := ratelimit.ParseTrustedProxies(c.RateLimit.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("trusted-proxies (TRUSTED_PROXIES): %w", err))
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			check(!c.CORS.AllowCredentials, "cors-allowed-origins (CORS_ALLOWED_ORIGINS) no puede ser * con cors-allow-credentials")
+			continue
+		}
+		u, err := url.Parse(origin)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && (u.Path == "" || u.Path == "/"),
+			"cors-allowed-origins (CORS_ALLOWED_ORIGINS): origen inválido %q, se espera p. ej. https://app.ax01.dev", origin)
+	}
+	for _, method := range c.CORS.AllowedMethods {
+		check(method == strings.ToUpper(method) && method != "", "cors-allowed-methods (CORS_ALLOWED_METHODS): método inválido %q", method)
+	}
+	check(c.CORS.MaxAge >= 0, "cors-max-age (CORS_MAX_AGE) no puede ser negativo")
+
+	if c.Seeds.CurriculaDir != "" {
+		info, err := os.Stat(c.Seeds.CurriculaDir)
+		check(err == nil && info.IsDir(), "curricula-dir (CURRICULA_DIR) no es un directorio: %s", c.Seeds.CurriculaDir)
+	}
+
+	check(c.Auth.Secret != "", "auth-secret (AUTH_SECRET) es obligatorio")
+	check(c.Auth.TokenTTL > 0, "auth-token-ttl (AUTH_TOKEN_TTL) debe ser positivo")
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLP:
+		u, err := url.Parse(c.Tracing.OTLPEndpoint)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"tracing-otlp-endpoint (OTEL_EXPORTER_OTLP_ENDPOINT) debe ser una URL http(s), p. ej. http://localhost:4318")
+	default:
+		errs = append(errs, fmt.Errorf("tracing-exporter (TRACING_EXPORTER) debe ser none, stdout u otlp"))
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing-sample-ratio (TRACING_SAMPLE_RATIO) debe estar entre 0 y 1")
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("log-level (LOG_LEVEL): %w", err))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("configuración inválida:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validate revisa los datos de conexión.
+func (c DatabaseConfig) validate() []error {
+	var errs []error
+	check := func(ok bool, message string) {
+		if !ok {
+			errs = append(errs, errors.New(message))
+		}
+	}
+
+	if c.URL == "" {
+		check(c.Host != "", "pg-host (PGHOST) es obligatorio si no se indica DATABASE_URL")
+		check(c.Port > 0 && c.Port <= 65535, "pg-port (PGPORT) debe estar entre 1 y 65535")
+		check(c.Name != "", "pg-database (PGDATABASE) es obligatorio si no se indica DATABASE_URL")
+	}
+	// Solo si los campos son válidos, para no reportar el mismo problema dos veces
+	if len(errs) == 0 {
+		if _, err := pgxpool.ParseConfig(c.connString()); err != nil {
+			errs = append(errs, errPoolConfig)
+		}
+	}
+	return errs
+}
+
+// El error de pgx puede incluir la cadena de conexión; no se propaga
+var errPoolConfig = errors.New("la configuración de la base de datos no es válida (revise DATABASE_URL o los campos PG*)")
+
+// connString arma la cadena de conexión a partir de URL o de los campos.
+func (c DatabaseConfig) connString() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	u := url.URL{
+		Scheme:   "postgresql",
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	if c.Password != "" {
+		u.User = url.UserPassword(c.User, c.Password)
+	} else if c.User != "" {
+		u.User = url.User(c.User)
+	}
+	return u.String()
+}
+
+// PoolConfig devuelve la configuración del pool de pgx con los límites indicados.
+func (c *Config) PoolConfig() (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(c.Database.connString())
+	if err != nil {
+		return nil, errPoolConfig
+	}
+
+	poolConfig.MaxConns = int32(c.Database.MaxConns)
+	poolConfig.MinConns = int32(c.Database.MinConns)
+	poolConfig.MaxConnLifetime = c.Database.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = c.Database.MaxConnIdleTime
+	return poolConfig, nil
+}
+
+// LogValue permite registrar la configuración sin exponer secretos.
+func (c Config) LogValue() slog.Value {
+	database := []slog.Attr{
+		slog.Int("max_conns", c.Database.MaxConns),
+		slog.Int("min_conns", c.Database.MinConns),
+		slog.String("max_conn_lifetime", c.Database.MaxConnLifetime.String()),
+		slog.String("max_conn_idle_time", c.Database.MaxConnIdleTime.String()),
+		slog.Int("connect_attempts", c.Database.ConnectAttempts),
+		slog.String("connect_backoff", c.Database.ConnectInitialBackoff.String()),
+		slog.String("connect_max_backoff", c.Database.ConnectMaxBackoff.String()),
+		slog.String("health_interval", c.Database.HealthInterval.String()),
+	}
+	if c.Database.URL != "" {
+		database = append(database, slog.String("url", redactURL(c.Database.URL)))
+	} else {
+		database = append(database,
+			slog.String("host", c.Database.Host),
+			slog.Int("port", c.Database.Port),
+			slog.String("database", c.Database.Name),
+			slog.String("sslmode", c.Database.SSLMode))
+	}
+
+	return slog.GroupValue(
+		slog.Group("database", attrsToAny(database)...),
+		slog.Group("http",
+			slog.String("addr", c.HTTP.Addr),
+			slog.String("read_header_timeout", c.HTTP.ReadHeaderTimeout.String()),
+			slog.String("read_timeout", c.HTTP.ReadTimeout.String()),
+			slog.String("write_timeout", c.HTTP.WriteTimeout.String()),
+			slog.String("idle_timeout", c.HTTP.IdleTimeout.String()),
+			slog.Int("max_header_bytes", c.HTTP.MaxHeaderBytes),
+			slog.Int64("max_body_bytes", c.HTTP.MaxBodyBytes),
+			slog.String("shutdown_timeout", c.HTTP.ShutdownTimeout.String())),
+		slog.Group("rate_limit",
+			slog.Float64("ip_rps", c.RateLimit.IPRate),
+			slog.Int("ip_burst", c.RateLimit.IPBurst),
+			slog.Float64("user_rps", c.RateLimit.UserRate),
+			slog.Int("user_burst", c.RateLimit.UserBurst),
+			slog.String("trusted_proxies", c.RateLimit.TrustedProxies)),
+		slog.Group("cors",
+			slog.Any("allowed_origins", c.CORS.AllowedOrigins),
+			slog.Any("allowed_methods", c.CORS.AllowedMethods),
+			slog.Any("allowed_headers", c.CORS.AllowedHeaders),
+			slog.Bool("allow_credentials", c.CORS.AllowCredentials),
+			slog.String("max_age", c.CORS.MaxAge.String())),
+		slog.Group("seeds",
+			slog.Bool("migrate", c.Seeds.Migrate),
+			slog.Bool("curricula", c.Seeds.Curricula),
+			slog.Bool("semesters", c.Seeds.Semesters),
+			slog.String("curricula_dir", c.Seeds.CurriculaDir)),
+		slog.String("auth_token_ttl", c.Auth.TokenTTL.String()),
+		slog.Group("tracing",
+			slog.String("exporter", c.Tracing.Exporter),
+			slog.String("otlp_endpoint", c.Tracing.OTLPEndpoint),
+			slog.Float64("sample_ratio", c.Tracing.SampleRatio)),
+		slog.String("log_level", c.LogLevel),
+	)
+}
+
+// redactURL oculta la contraseña de una URL. Las cadenas clave=valor no se
+// muestran porque pueden incluir password=.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return logging.Redacted
+	}
+	return u.Redacted()
+}
+
+// listValue es un flag con valores separados por comas.
+type listValue []string
+
+func (l *listValue) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+func attrsToAny(attrs []slog.Attr) []any {
+	values := make([]any, len(attrs))
+	for i, attr := range attrs {
+		values[i] = attr
+	}
+	return values
+}
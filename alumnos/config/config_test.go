@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadDatabase(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte(`{"pg-host": "archivo", "pg-database": "alumnos_archivo"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		databaseURL string
+		env         map[string]string
+		want        string // cadena de conexión esperada
+		wantErr     string
+	}{
+		{"valores por defecto", "", nil, "postgresql://root@db:5432/alumnos?sslmode=disable", ""},
+		{"variables PG*", "", map[string]string{"PGHOST": "pg", "PGUSER": "app", "PGPASSWORD": "secreto"},
+			"postgresql://app:secreto@pg:5432/alumnos?sslmode=disable", ""},
+		{"DATABASE_URL", "", map[string]string{"DATABASE_URL": "postgresql://env/alumnos", "PGHOST": "pg"},
+			"postgresql://env/alumnos", ""},
+		{"archivo de configuración", "", map[string]string{"CONFIG_FILE": file},
+			"postgresql://root@archivo:5432/alumnos_archivo?sslmode=disable", ""},
+		{"-dsn gana al entorno", "postgresql://flag/alumnos", map[string]string{"DATABASE_URL": "postgresql://env/alumnos"},
+			"postgresql://flag/alumnos", ""},
+		{"no exige AUTH_SECRET", "", map[string]string{"PGHOST": "pg"}, "postgresql://root@pg:5432/alumnos?sslmode=disable", ""},
+		{"puerto inválido", "", map[string]string{"PGPORT": "70000"}, "", "pg-port (PGPORT)"},
+		{"URL inválida", "postgresql://:puerto/", nil, "", "la configuración de la base de datos no es válida"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadDatabase(tt.databaseURL, func(name string) string { return tt.env[name] })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadDatabase() error = %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadDatabase(): %v", err)
+			}
+			if got := cfg.Database.connString(); got != tt.want {
+				t.Errorf("cadena de conexión = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// El servidor sigue exigiendo la configuración completa.
+func TestLoadRequiresAuthSecret(t *testing.T) {
+	_, err := Load(nil, func(string) string { return "" })
+	if err == nil || !strings.Contains(err.Error(), "auth-secret (AUTH_SECRET) es obligatorio") {
+		t.Fatalf("Load() error = %v, se esperaba que exigiera AUTH_SECRET", err)
+	}
+}
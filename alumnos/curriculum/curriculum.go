@@ -0,0 +1,106 @@
+package curriculum
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+)
+
+// Planes de estudio incluidos en el binario. Cada archivo describe una carrera.
+//
+//go:embed data/*.json
+var embedded embed.FS
+
+// Curriculum es el plan de estudios de una carrera tal como se versiona en data/.
+type Curriculum struct {
+	Version  string    `json:"version"`
+	Course   string    `json:"course"`
+	Subjects []Subject `json:"subjects"`
+}
+
+type Subject struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Coins int    `json:"coins"`
+}
+
+// Embedded devuelve los planes de estudio incluidos en el binario.
+func Embedded() ([]Curriculum, error) {
+	sub, err := fs.Sub(embedded, "data")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// FromDir carga los planes de estudio de dir, o los incluidos en el binario si dir está vacío.
+func FromDir(dir string) ([]Curriculum, error) {
+	if dir == "" {
+		return Embedded()
+	}
+	return Load(os.DirFS(dir))
+}
+
+// Load lee y valida todos los archivos *.json en la raíz de fsys, en orden alfabético.
+func Load(fsys fs.FS) ([]Curriculum, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, fmt.Errorf("error al listar planes de estudio: %w", err)
+	}
+	sort.Strings(files)
+
+	seen := make(map[string]string)
+	var curricula []Curriculum
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("error al leer %s: %w", file, err)
+		}
+
+		var c Curriculum
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("error al decodificar %s: %w", file, err)
+		}
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("plan de estudios inválido en %s: %w", file, err)
+		}
+
+		if other, exists := seen[c.Course]; exists {
+			return nil, fmt.Errorf("la carrera %q aparece en %s y en %s", c.Course, other, path.Base(file))
+		}
+		seen[c.Course] = path.Base(file)
+
+		curricula = append(curricula, c)
+	}
+
+	return curricula, nil
+}
+
+func (c Curriculum) validate() error {
+	if c.Version == "" {
+		return fmt.Errorf("falta el campo 'version'")
+	}
+	if c.Course == "" {
+		return fmt.Errorf("falta el campo 'course'")
+	}
+
+	keys := make(map[string]bool)
+	for i, subject := range c.Subjects {
+		if subject.Key == "" || subject.Name == "" {
+			return fmt.Errorf("la materia #%d no tiene 'key' o 'name'", i+1)
+		}
+		if subject.Coins <= 0 {
+			return fmt.Errorf("la materia %s debe tener 'coins' positivos", subject.Key)
+		}
+		if keys[subject.Key] {
+			return fmt.Errorf("la clave %s está repetida", subject.Key)
+		}
+		keys[subject.Key] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Backoff define los reintentos al esperar la base de datos. El intervalo se
+// duplica en cada intento hasta llegar a Max.
+type Backoff struct {
+	Attempts int           // intentos en total; 0 reintenta sin límite
+	Initial  time.Duration // espera tras el primer fallo
+	Max      time.Duration // espera máxima entre intentos
+}
+
+// pingTimeout limita cada intento para no quedar colgado con un host que no responde.
+const pingTimeout = 5 * time.Second
+
+// WaitForDatabase hace ping a la base de datos hasta que responde, se agotan
+// los intentos o ctx se cancela. pgxpool.New no abre conexiones, así que sin
+// este paso un servidor inaccesible solo se detecta en la primera consulta.
+func WaitForDatabase(ctx context.Context, pool *pgxpool.Pool, backoff Backoff, logger *slog.Logger) error {
+	delay := backoff.Initial
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := pool.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			logger.Info("conexión a la base de datos exitosa", "attempt", attempt)
+			return nil
+		}
+
+		if backoff.Attempts > 0 && attempt >= backoff.Attempts {
+			return fmt.Errorf("la base de datos no respondió tras %d intentos: %w", attempt, err)
+		}
+		logger.Warn("la base de datos no responde; reintentando",
+			"attempt", attempt, "retry_in", delay.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("espera de la base de datos cancelada: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > backoff.Max {
+			delay = backoff.Max
+		}
+	}
+}
+
+// Monitor comprueba periódicamente la base de datos una vez que el servidor
+// arrancó. Si deja de responder, el servicio sigue atendiendo en modo
+// degradado (las consultas fallan con 503) y pgxpool vuelve a conectar solo
+// cuando se recupera; el monitor registra ambas transiciones.
+type Monitor struct {
+	pool     *pgxpool.Pool
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	healthy bool
+}
+
+func NewMonitor(pool *pgxpool.Pool, interval time.Duration, logger *slog.Logger) *Monitor {
+	// WaitForDatabase ya confirmó la conexión al arrancar
+	return &Monitor{pool: pool, interval: interval, logger: logger, healthy: true}
+}
+
+// Run hace ping cada intervalo hasta que ctx se cancela.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check(ctx)
+		}
+	}
+}
+
+// Check hace un ping inmediato y actualiza el estado.
+func (m *Monitor) Check(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	err := m.pool.Ping(pingCtx)
+	cancel()
+
+	m.mu.Lock()
+	wasHealthy := m.healthy
+	m.healthy = err == nil
+	m.mu.Unlock()
+
+	switch {
+	case err != nil && wasHealthy:
+		m.logger.Error("la base de datos dejó de responder; servicio degradado", "error", err)
+	case err == nil && !wasHealthy:
+		m.logger.Info("la base de datos volvió a responder")
+	}
+	return err
+}
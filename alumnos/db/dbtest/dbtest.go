@@ -0,0 +1,103 @@
+// Package dbtest prepara bases de datos aisladas para las pruebas que
+// necesitan PostgreSQL. Usa la cadena de conexión de TEST_DATABASE_URL; sin
+// ella las pruebas se omiten, así que go test ./... funciona sin base de datos.
+package dbtest
+
+import (
+	postgres "alumnos/db"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// extensionsLockID serializa la creación de extensiones entre paquetes de
+// prueba que corren en paralelo.
+const extensionsLockID = 7462_0002
+
+// Open crea un esquema vacío con nombre único y devuelve un pool cuyo
+// search_path empieza en él. El esquema se borra al terminar la prueba.
+func Open(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL no está definida; se omite la prueba con PostgreSQL")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("error al conectar a TEST_DATABASE_URL: %v", err)
+	}
+
+	// Las extensiones van en public: alumn_search_name llama a public.unaccent
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	_, err = admin.Exec(ctx, fmt.Sprintf(`
+		BEGIN;
+		SELECT pg_advisory_xact_lock(%d);
+		CREATE EXTENSION IF NOT EXISTS unaccent SCHEMA public;
+		CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public;
+		COMMIT;
+		CREATE SCHEMA %s;
+	`, extensionsLockID, schema))
+	if err != nil {
+		admin.Close(ctx)
+		t.Fatalf("error al crear el esquema de prueba: %v", err)
+	}
+
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("TEST_DATABASE_URL no es válida: %v", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("error al crear el pool de prueba: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+		if _, err := admin.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			t.Errorf("error al borrar el esquema de prueba %s: %v", schema, err)
+		}
+		admin.Close(ctx)
+	})
+	return pool
+}
+
+// Migrated crea la base como en producción: schema.sql (lo que ejecuta
+// docker-entrypoint-initdb.d) y después las migraciones.
+func Migrated(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	pool := Open(t)
+	ExecFile(t, pool, SchemaPath())
+	if _, err := postgres.Migrate(context.Background(), pool); err != nil {
+		t.Fatalf("error al aplicar migraciones: %v", err)
+	}
+	return pool
+}
+
+// ExecFile ejecuta un archivo con varias sentencias SQL.
+func ExecFile(t testing.TB, pool *pgxpool.Pool, path string) {
+	t.Helper()
+	sql, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error al leer %s: %v", path, err)
+	}
+	// Sin argumentos pgx usa el protocolo simple, que admite varias sentencias
+	if _, err := pool.Exec(context.Background(), string(sql)); err != nil {
+		t.Fatalf("error al ejecutar %s: %v", filepath.Base(path), err)
+	}
+}
+
+// SchemaPath devuelve la ruta de schema.sql, en la raíz del repositorio.
+func SchemaPath() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "schema.sql")
+}
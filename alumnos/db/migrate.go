@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Migraciones para bases creadas con una versión anterior de schema.sql.
+// Cada archivo se aplica una sola vez, en orden, dentro de su propia transacción.
+// Cada cambio de schema.sql lleva su propia migración idempotente, de modo que
+// una base creada con cualquier versión anterior queda igual que una nueva.
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// migrationLockID identifica el advisory lock que evita que dos instancias migren a la vez.
+const migrationLockID = 7462_0001
+
+// Migrate aplica las migraciones pendientes y devuelve los nombres de las que se aplicaron.
+func Migrate(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear schema_migrations: %w", err)
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("error al listar migraciones: %w", err)
+	}
+	sort.Strings(files)
+
+	var applied []string
+	for _, file := range files {
+		name := file[len("migrations/"):]
+		ok, err := applyMigration(ctx, pool, name, file)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied = append(applied, name)
+		}
+	}
+
+	return applied, nil
+}
+
+// PendingMigrations devuelve las migraciones incluidas en el binario que aún
+// no se aplicaron en la base de datos.
+func PendingMigrations(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("error al listar migraciones: %w", err)
+	}
+	sort.Strings(files)
+
+	// Antes de la primera migración la tabla no existe y todo está pendiente
+	var tracked bool
+	if err := pool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&tracked); err != nil {
+		return nil, fmt.Errorf("error al consultar migraciones aplicadas: %w", err)
+	}
+
+	applied := make(map[string]bool)
+	if tracked {
+		rows, err := pool.Query(ctx, `SELECT name FROM schema_migrations`)
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar migraciones aplicadas: %w", err)
+		}
+		names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar migraciones aplicadas: %w", err)
+		}
+		for _, name := range names {
+			applied[name] = true
+		}
+	}
+
+	var pending []string
+	for _, file := range files {
+		if name := file[len("migrations/"):]; !applied[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+func applyMigration(ctx context.Context, pool *pgxpool.Pool, name, file string) (bool, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return false, fmt.Errorf("error al bloquear migraciones: %w", err)
+	}
+
+	var done bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE name = $1)`, name).Scan(&done)
+	if err != nil {
+		return false, fmt.Errorf("error al consultar migración %s: %w", name, err)
+	}
+	if done {
+		return false, nil
+	}
+
+	sql, err := migrations.ReadFile(file)
+	if err != nil {
+		return false, fmt.Errorf("error al leer migración %s: %w", name, err)
+	}
+
+	// Sin argumentos pgx usa el protocolo simple, que admite varias sentencias
+	if _, err := tx.Exec(ctx, string(sql)); err != nil {
+		return false, fmt.Errorf("error al aplicar migración %s: %w", name, err)
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (name) VALUES ($1)`, name); err != nil {
+		return false, fmt.Errorf("error al registrar migración %s: %w", name, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return true, nil
+}
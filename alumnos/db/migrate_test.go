@@ -0,0 +1,105 @@
+package postgres_test
+
+import (
+	postgres "alumnos/db"
+	"alumnos/db/dbtest"
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Una base creada con el schema.sql original debe quedar, después de las
+// migraciones, igual que una creada con el schema.sql actual.
+func TestMigrateFromBaseline(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"base anterior a las migraciones", "testdata/baseline_schema.sql"},
+		{"base nueva", dbtest.SchemaPath()},
+	}
+
+	shapes := make([][]string, len(tests))
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := dbtest.Open(t)
+			dbtest.ExecFile(t, pool, tt.schema)
+
+			applied, err := postgres.Migrate(ctx, pool)
+			if err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+			pending, err := postgres.PendingMigrations(ctx, pool)
+			if err != nil {
+				t.Fatalf("PendingMigrations: %v", err)
+			}
+			if len(pending) > 0 {
+				t.Errorf("quedaron migraciones pendientes: %v", pending)
+			}
+
+			// Cada migración se aplica una sola vez
+			again, err := postgres.Migrate(ctx, pool)
+			if err != nil {
+				t.Fatalf("Migrate otra vez: %v", err)
+			}
+			if len(again) > 0 {
+				t.Errorf("la segunda ejecución volvió a aplicar %v", again)
+			}
+			if len(applied) == 0 {
+				t.Error("no se aplicó ninguna migración")
+			}
+
+			shapes[i] = schemaShape(t, pool)
+		})
+	}
+
+	if shapes[0] == nil || shapes[1] == nil {
+		return
+	}
+	for _, item := range shapes[0] {
+		if !slices.Contains(shapes[1], item) {
+			t.Errorf("solo en la base migrada: %s", item)
+		}
+	}
+	for _, item := range shapes[1] {
+		if !slices.Contains(shapes[0], item) {
+			t.Errorf("solo en la base nueva: %s", item)
+		}
+	}
+}
+
+// schemaShape lista columnas, restricciones e índices del esquema de la prueba.
+func schemaShape(t *testing.T, pool *pgxpool.Pool) []string {
+	t.Helper()
+	rows, err := pool.Query(context.Background(), `
+		SELECT 'column ' || table_name || '.' || column_name || ' ' || data_type || ' ' || is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'
+		UNION ALL
+		SELECT 'constraint ' || conrelid::regclass::text || '.' || conname
+		FROM pg_constraint
+		WHERE connamespace = current_schema()::regnamespace
+		UNION ALL
+		SELECT 'index ' || tablename || '.' || indexname
+		FROM pg_indexes
+		WHERE schemaname = current_schema() AND tablename <> 'schema_migrations'
+		UNION ALL
+		SELECT 'trigger ' || event_object_table || '.' || trigger_name || ' ' || event_manipulation
+		FROM information_schema.triggers
+		WHERE trigger_schema = current_schema()
+		ORDER BY 1;
+	`)
+	if err != nil {
+		t.Fatalf("error al leer el esquema: %v", err)
+	}
+	shape, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatalf("error al leer el esquema: %v", err)
+	}
+	return shape
+}
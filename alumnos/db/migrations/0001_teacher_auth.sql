@@ -0,0 +1,8 @@
+-- Login de profesores: el correo identifica a la cuenta.
+-- En bases nuevas ya viene en schema.sql y esta migración no hace nada.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'teacher_email_key') THEN
+        ALTER TABLE teacher ADD CONSTRAINT teacher_email_key UNIQUE (email);
+    END IF;
+END $$;
@@ -0,0 +1,22 @@
+-- Roles de profesores y administradores, credenciales opcionales de alumnos
+-- y materias que imparte cada profesor.
+ALTER TABLE teacher ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'teacher' CHECK (role IN ('teacher', 'admin'));
+ALTER TABLE alumn ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+ALTER TABLE alumn ADD COLUMN IF NOT EXISTS password VARCHAR(255);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'alumn_email_key') THEN
+        ALTER TABLE alumn ADD CONSTRAINT alumn_email_key UNIQUE (email);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS teacher_subjects (
+    id SERIAL PRIMARY KEY,
+    teacher_id INTEGER NOT NULL,
+    subject_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (teacher_id, subject_id),
+    FOREIGN KEY (teacher_id) REFERENCES teacher(id) ON DELETE CASCADE,
+    FOREIGN KEY (subject_id) REFERENCES academyc_history(id) ON DELETE CASCADE
+);
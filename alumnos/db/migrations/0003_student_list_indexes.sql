@@ -0,0 +1,4 @@
+-- Índices para el listado paginado, filtrado y ordenado de alumnos
+CREATE INDEX IF NOT EXISTS idx_alumn_lastname ON alumn (lastname1, lastname2, name, id);
+CREATE INDEX IF NOT EXISTS idx_alumn_course_semester ON alumn (course_id, current_semester);
+CREATE INDEX IF NOT EXISTS idx_alumn_created_at ON alumn (created_at);
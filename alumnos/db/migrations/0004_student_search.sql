@@ -0,0 +1,12 @@
+-- Búsqueda de alumnos por nombre sin distinguir acentos ni mayúsculas
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() no es IMMUTABLE; este envoltorio permite usarlo en índices
+CREATE OR REPLACE FUNCTION alumn_search_name(name TEXT, lastname1 TEXT, lastname2 TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(public.unaccent('public.unaccent', name || ' ' || lastname1 || ' ' || COALESCE(lastname2, '')));
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_alumn_search_name
+ON alumn USING gin (alumn_search_name(name, lastname1, lastname2) gin_trgm_ops);
@@ -0,0 +1,2 @@
+-- Borrado lógico de alumnos; conserva el historial de calificaciones
+ALTER TABLE alumn ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
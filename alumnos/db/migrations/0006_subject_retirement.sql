@@ -0,0 +1,2 @@
+-- Baja de materias del plan de estudios sin perder el historial de los alumnos
+ALTER TABLE academyc_history ADD COLUMN IF NOT EXISTS retired_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- Versión del plan de estudios aplicado a cada carrera (alumnos/curriculum/data)
+ALTER TABLE cat_courses ADD COLUMN IF NOT EXISTS curriculum_version VARCHAR(50);
@@ -0,0 +1,72 @@
+-- Los seeds anteriores insertaban el catálogo completo en cada arranque porque
+-- ON CONFLICT DO NOTHING no tenía una restricción única contra la cual chocar.
+-- Se conserva el registro más antiguo de cada clave natural, se reapuntan las
+-- referencias hacia él y después se agregan las restricciones únicas.
+
+-- Carreras repetidas por nombre
+CREATE TEMP TABLE course_remap ON COMMIT DROP AS
+SELECT id AS old_id, keep_id
+FROM (SELECT id, MIN(id) OVER (PARTITION BY name) AS keep_id FROM cat_courses) ranked
+WHERE id <> keep_id;
+
+UPDATE alumn a
+SET course_id = r.keep_id
+FROM course_remap r
+WHERE a.course_id = r.old_id;
+
+-- Las materias de la carrera repetida pasan a la conservada; si la clave ya
+-- existe allí, la deduplicación de materias de abajo las une
+UPDATE academyc_history ah
+SET course_id = r.keep_id
+FROM course_remap r
+WHERE ah.course_id = r.old_id;
+
+DELETE FROM cat_courses c
+USING course_remap r
+WHERE c.id = r.old_id;
+
+-- Materias repetidas por (course_id, key)
+CREATE TEMP TABLE subject_remap ON COMMIT DROP AS
+SELECT id AS old_id, keep_id
+FROM (SELECT id, MIN(id) OVER (PARTITION BY course_id, key) AS keep_id FROM academyc_history) ranked
+WHERE id <> keep_id;
+
+UPDATE semester_course sc
+SET subject_id = r.keep_id
+FROM subject_remap r
+WHERE sc.subject_id = r.old_id;
+
+-- teacher_subjects es único por (teacher_id, subject_id): se quitan las
+-- asignaciones que quedarían repetidas antes de reapuntar las demás
+DELETE FROM teacher_subjects ts
+USING subject_remap r
+WHERE ts.subject_id = r.old_id
+  AND EXISTS (
+      SELECT 1
+      FROM teacher_subjects other
+      LEFT JOIN subject_remap ro ON ro.old_id = other.subject_id
+      WHERE other.teacher_id = ts.teacher_id
+        AND other.id <> ts.id
+        AND COALESCE(ro.keep_id, other.subject_id) = r.keep_id
+        AND (ro.old_id IS NULL OR other.id < ts.id)
+  );
+
+UPDATE teacher_subjects ts
+SET subject_id = r.keep_id
+FROM subject_remap r
+WHERE ts.subject_id = r.old_id;
+
+-- Ya sin referencias, borrar las copias no elimina nada en cascada
+DELETE FROM academyc_history ah
+USING subject_remap r
+WHERE ah.id = r.old_id;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_cat_courses_name') THEN
+        ALTER TABLE cat_courses ADD CONSTRAINT uq_cat_courses_name UNIQUE (name);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_academyc_history_course_key') THEN
+        ALTER TABLE academyc_history ADD CONSTRAINT uq_academyc_history_course_key UNIQUE (course_id, key);
+    END IF;
+END $$;
@@ -0,0 +1,22 @@
+-- Periodos académicos del calendario, separados de los niveles de cat_semesters
+CREATE TABLE IF NOT EXISTS academic_periods (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_academic_periods_code UNIQUE (code),
+    CONSTRAINT chk_academic_periods_dates CHECK (end_date > start_date)
+);
+
+ALTER TABLE semester_course ADD COLUMN IF NOT EXISTS period_id INTEGER;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_semester_course_period_id') THEN
+        ALTER TABLE semester_course
+        ADD CONSTRAINT fk_semester_course_period_id
+        FOREIGN KEY (period_id) REFERENCES academic_periods(id);
+    END IF;
+END $$;
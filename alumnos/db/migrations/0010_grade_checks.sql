@@ -0,0 +1,14 @@
+-- Reglas de calificaciones de alumnos/validation también en la base de datos.
+-- NOT VALID aplica la regla a filas nuevas sin fallar por calificaciones
+-- fuera de rango que ya existan; se pueden corregir y validar después.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_partial_grades_grade') THEN
+        ALTER TABLE partial_grades
+        ADD CONSTRAINT chk_partial_grades_grade CHECK (grade BETWEEN 0 AND 10) NOT VALID;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_partial_grades_partial_number') THEN
+        ALTER TABLE partial_grades
+        ADD CONSTRAINT chk_partial_grades_partial_number CHECK (partial_number IN (1, 2)) NOT VALID;
+    END IF;
+END $$;
@@ -0,0 +1,25 @@
+-- Avisa a los servidores en ejecución cuando cambian los catálogos para que
+-- vacíen su caché (alumnos/catalog). Se dispara una vez por sentencia, también
+-- con los cambios hechos fuera de la API (cmd/curricula, psql).
+CREATE OR REPLACE FUNCTION notify_catalog_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('catalog_changed', TG_TABLE_NAME);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER notify_cat_courses_change
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON cat_courses
+FOR EACH STATEMENT
+EXECUTE FUNCTION notify_catalog_change();
+
+CREATE OR REPLACE TRIGGER notify_academyc_history_change
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON academyc_history
+FOR EACH STATEMENT
+EXECUTE FUNCTION notify_catalog_change();
+
+CREATE OR REPLACE TRIGGER notify_cat_semesters_change
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON cat_semesters
+FOR EACH STATEMENT
+EXECUTE FUNCTION notify_catalog_change();
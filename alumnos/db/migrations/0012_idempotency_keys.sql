@@ -0,0 +1,16 @@
+-- Respuestas guardadas de los POST que aceptan el header Idempotency-Key, para
+-- que un reintento del cliente no registre dos veces al alumno o la inscripción.
+-- status_code NULL indica que la solicitud original sigue en curso.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope VARCHAR(255) NOT NULL, -- ruta y usuario que envió la clave
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL, -- sha256 del cuerpo de la solicitud
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
@@ -0,0 +1,30 @@
+-- Un alumno no puede inscribirse dos veces en la misma materia y semestre:
+-- las calificaciones se repartían entre las copias y el promedio del semestre
+-- las contaba dos veces. Las copias existentes se unen antes con
+-- dedupe-enrollments -apply, que viene en la imagen (ver "Actualizar" en el
+-- README); mientras queden, esta migración falla sin cambiar nada y el
+-- servidor no arranca.
+DO $$
+DECLARE
+    duplicates INTEGER;
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_semester_course_enrollment') THEN
+        RETURN;
+    END IF;
+
+    SELECT count(*) INTO duplicates
+    FROM (
+        SELECT 1
+        FROM semester_course
+        GROUP BY alumn_id, semester_id, subject_id
+        HAVING count(*) > 1
+    ) repeated;
+
+    IF duplicates > 0 THEN
+        -- El mensaje llega al log del servidor; pgx no muestra el HINT
+        RAISE EXCEPTION 'hay % inscripciones repetidas en semester_course; únalas con /app/dedupe-enrollments -apply (ver "Actualizar" en el README) y vuelva a arrancar', duplicates;
+    END IF;
+
+    ALTER TABLE semester_course
+    ADD CONSTRAINT uq_semester_course_enrollment UNIQUE (alumn_id, semester_id, subject_id);
+END $$;
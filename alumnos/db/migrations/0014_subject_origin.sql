@@ -0,0 +1,4 @@
+-- Origen de cada materia: la versión del plan de estudios que la escribió por
+-- última vez, o NULL si la creó o la modificó un administrador. La
+-- sincronización del plan solo actualiza o da de baja materias con versión.
+ALTER TABLE academyc_history ADD COLUMN IF NOT EXISTS curriculum_version VARCHAR(50);
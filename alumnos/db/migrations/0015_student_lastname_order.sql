@@ -0,0 +1,5 @@
+-- El listado por apellido pone primero a quien no tiene segundo apellido.
+-- El índice guarda lastname2 con NULLS FIRST para que ese orden, y su inverso
+-- al ordenar descendente, se lean del índice sin un Sort aparte.
+DROP INDEX IF EXISTS idx_alumn_lastname;
+CREATE INDEX idx_alumn_lastname ON alumn (lastname1, lastname2 NULLS FIRST, name, id);
@@ -0,0 +1,87 @@
+// Package logging configura el logger estructurado (log/slog) de la
+// aplicación y lo transporta en el contexto de cada solicitud.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Redacted reemplaza el valor de los atributos con datos personales.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys son los atributos que nunca se escriben en claro, sin
+// importar el grupo en que aparezcan.
+var sensitiveKeys = map[string]bool{
+	"name":          true,
+	"lastname1":     true,
+	"lastname2":     true,
+	"email":         true,
+	"password":      true,
+	"token":         true,
+	"authorization": true,
+	"body":          true,
+}
+
+// ParseLevel interpreta LOG_LEVEL: debug, info, warn o error. Vacío equivale a info.
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("nivel de log desconocido %q (debug, info, warn, error)", value)
+}
+
+// New crea un logger JSON que redacta los atributos con datos personales.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+}
+
+// redact reemplaza los atributos sensibles y todo lo que vaya dentro de un
+// grupo sensible. slog no llama a ReplaceAttr con los grupos, solo con sus
+// hojas, así que el nombre del grupo se revisa en groups.
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Redacted)
+	}
+	for _, group := range groups {
+		if sensitiveKeys[strings.ToLower(group)] {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+	return attr
+}
+
+type loggerKey struct{}
+
+// WithLogger guarda en el contexto el logger de la solicitud.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext devuelve el logger de la solicitud o, fuera de una solicitud,
+// fallback. Si fallback es nil se usa slog.Default().
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return slog.Default()
+}
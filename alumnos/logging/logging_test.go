@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(logger *slog.Logger)
+		want map[string]any
+	}{
+		{
+			name: "claves sensibles",
+			log: func(logger *slog.Logger) {
+				logger.Info("m",
+					"name", "Ana", "lastname1", "García", "lastname2", "López",
+					"email", "ana@example.com", "password", "secreto", "token", "eyJ...",
+					"authorization", "Bearer eyJ...", "body", `{"name":"Ana"}`,
+					"course_id", 3)
+			},
+			want: map[string]any{
+				"name": Redacted, "lastname1": Redacted, "lastname2": Redacted,
+				"email": Redacted, "password": Redacted, "token": Redacted,
+				"authorization": Redacted, "body": Redacted,
+				"course_id": 3.0,
+			},
+		},
+		{
+			name: "sin distinguir mayúsculas",
+			log: func(logger *slog.Logger) {
+				logger.Info("m", "Email", "ana@example.com", "AUTHORIZATION", "Bearer eyJ...", "LastName1", "García", "Path", "/v1/alumnos")
+			},
+			want: map[string]any{"Email": Redacted, "AUTHORIZATION": Redacted, "LastName1": Redacted, "Path": "/v1/alumnos"},
+		},
+		{
+			name: "dentro de grupos",
+			log: func(logger *slog.Logger) {
+				logger.Info("m", slog.Group("request",
+					slog.String("method", "POST"),
+					slog.Group("user", slog.String("Email", "ana@example.com"), slog.Int("id", 7)),
+					slog.String("token", "eyJ..."),
+				))
+			},
+			want: map[string]any{"request": map[string]any{
+				"method": "POST",
+				"user":   map[string]any{"Email": Redacted, "id": 7.0},
+				"token":  Redacted,
+			}},
+		},
+		{
+			name: "grupo sensible",
+			log: func(logger *slog.Logger) {
+				logger.Info("m", slog.Group("Body", slog.String("curp", "GALA900101"), slog.Group("extra", slog.Int("edad", 20))))
+			},
+			want: map[string]any{"Body": map[string]any{"curp": Redacted, "extra": map[string]any{"edad": Redacted}}},
+		},
+		{
+			name: "logger con grupo y atributos",
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("alumno").With("name", "Ana").Info("m", "id", 1)
+			},
+			want: map[string]any{"alumno": map[string]any{"name": Redacted, "id": 1.0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(New(&buf, slog.LevelInfo))
+
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("el log no es JSON: %v\n%s", err, buf.String())
+			}
+			// Los atributos propios del registro no se redactan
+			for _, key := range []string{"time", "level", "msg"} {
+				if _, ok := got[key]; !ok {
+					t.Errorf("falta %q en %s", key, buf.String())
+				}
+				delete(got, key)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("log = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"", slog.LevelInfo, false},
+		{"DEBUG", slog.LevelDebug, false},
+		{" warning ", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"trace", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; se esperaba %v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
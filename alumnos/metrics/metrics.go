@@ -0,0 +1,132 @@
+// Package metrics expone las métricas del servicio en formato Prometheus:
+// solicitudes HTTP por ruta, consultas por método del repositorio, estado
+// del pool de pgx y contadores del dominio escolar.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "alumnos"
+
+// Metrics agrupa los colectores del servicio. Todos los métodos aceptan un
+// receptor nil para que los handlers funcionen sin métricas configuradas.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	queryDuration *prometheus.HistogramVec
+	queryErrors   *prometheus.CounterVec
+
+	studentsRegistered prometheus.Counter
+	enrollments        prometheus.Counter
+	gradesRegistered   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Solicitudes HTTP atendidas por método, patrón de ruta y código de estado.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latencia de las solicitudes HTTP por método, patrón de ruta y código de estado.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"method", "route", "status"}),
+
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Duración de las consultas SQL por operación (método del repositorio, migraciones...).",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_errors_total",
+			Help:      "Consultas SQL fallidas por operación (sin contar las que no devuelven filas).",
+		}, []string{"method"}),
+
+		studentsRegistered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "students_registered_total",
+			Help:      "Alumnos registrados.",
+		}),
+		enrollments: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "enrollments_created_total",
+			Help:      "Materias inscritas, al registrar alumnos o al inscribirlos en un semestre.",
+		}),
+		gradesRegistered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grades_registered_total",
+			Help:      "Calificaciones parciales registradas por número de parcial.",
+		}, []string{"partial"}),
+	}
+
+	m.registry.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.queryDuration, m.queryErrors,
+		m.studentsRegistered, m.enrollments, m.gradesRegistered,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler sirve GET /metrics. Sin métricas configuradas responde 404.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest registra una solicitud atendida. route es el patrón de
+// http.ServeMux, no la URL, para que los IDs no multipliquen las series.
+func (m *Metrics) ObserveRequest(r *http.Request, route string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(r.Method, route, code).Inc()
+	m.httpDuration.WithLabelValues(r.Method, route, code).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) StudentRegistered() {
+	if m == nil {
+		return
+	}
+	m.studentsRegistered.Inc()
+}
+
+// EnrollmentsCreated suma las materias inscritas en una operación.
+func (m *Metrics) EnrollmentsCreated(count int) {
+	if m == nil {
+		return
+	}
+	m.enrollments.Add(float64(count))
+}
+
+func (m *Metrics) GradeRegistered(partialNumber int) {
+	if m == nil {
+		return
+	}
+	m.gradesRegistered.WithLabelValues(strconv.Itoa(partialNumber)).Inc()
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterPool publica pgxpool.Stat(). Los valores se leen en cada consulta a
+// /metrics, por lo que no hace falta actualizarlos periódicamente.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(&poolCollector{pool: pool})
+}
+
+var (
+	poolAcquiredConns = poolDesc("acquired_conns", "Conexiones en uso.")
+	poolIdleConns     = poolDesc("idle_conns", "Conexiones inactivas disponibles.")
+	poolTotalConns    = poolDesc("total_conns", "Conexiones abiertas, en uso, inactivas o en construcción.")
+	poolConstructing  = poolDesc("constructing_conns", "Conexiones que se están abriendo.")
+	poolMaxConns      = poolDesc("max_conns", "Tamaño máximo del pool.")
+	poolAcquires      = poolDesc("acquires_total", "Conexiones obtenidas del pool.")
+	poolEmptyAcquires = poolDesc("empty_acquires_total", "Veces que hubo que esperar porque el pool no tenía conexiones libres.")
+	poolCanceled      = poolDesc("canceled_acquires_total", "Esperas de conexión canceladas por el contexto.")
+	poolAcquireWait   = poolDesc("acquire_duration_seconds_total", "Tiempo acumulado esperando una conexión del pool.")
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+}
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		poolAcquiredConns, poolIdleConns, poolTotalConns, poolConstructing, poolMaxConns,
+		poolAcquires, poolEmptyAcquires, poolCanceled, poolAcquireWait,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolConstructing, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceled, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireWait, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
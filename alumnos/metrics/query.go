@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"alumnos/repository"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryTracer devuelve un pgx.QueryTracer que mide cada consulta y la
+// atribuye a la operación del contexto (ver repository.WithOperation).
+func (m *Metrics) QueryTracer() pgx.QueryTracer {
+	return &queryTracer{metrics: m}
+}
+
+type queryTracer struct {
+	metrics *Metrics
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	method string
+	start  time.Time
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{method: repository.Operation(ctx), start: time.Now()})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	started, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok || t.metrics == nil {
+		return
+	}
+
+	t.metrics.queryDuration.WithLabelValues(started.method).Observe(time.Since(started.start).Seconds())
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		t.metrics.queryErrors.WithLabelValues(started.method).Inc()
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
 
 type Alumno struct {
 	ID              int       `json:"id"`
@@ -20,8 +24,82 @@ type RegisterAlumnRequest struct {
 	CourseID        int         `json:"course_id"`
 	CurrentCourseID int         `json:"current_course_id"`
 	Subjects        []SubjectID `json:"subjects"`
+	PeriodCode      string      `json:"period_code,omitempty"` // por defecto, el periodo vigente
+	Email           string      `json:"email,omitempty"`       // opcional, habilita el acceso del alumno
+	Password        string      `json:"password,omitempty"`    // requerido si se envía email
+}
+
+type AlumnCredentials struct {
+	ID       int
+	Email    string
+	Password string // hash bcrypt
+}
+
+// UpdateAlumnRequest usa punteros para distinguir los campos omitidos en un PATCH.
+type UpdateAlumnRequest struct {
+	Name      *string `json:"name"`
+	Lastname1 *string `json:"lastname1"`
+	Lastname2 *string `json:"lastname2"`
+	CourseID  *int    `json:"course_id"`
+
+	// Lastname2Set indica que se envió lastname2; con null o "" se borra.
+	Lastname2Set bool `json:"-"`
+}
+
+func (r *UpdateAlumnRequest) UnmarshalJSON(data []byte) error {
+	type fields UpdateAlumnRequest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode((*fields)(r)); err != nil {
+		return err
+	}
+
+	// Un puntero no distingue null de un campo omitido; json.RawMessage sí
+	var presence struct {
+		Lastname2 json.RawMessage `json:"lastname2"`
+	}
+	if err := json.Unmarshal(data, &presence); err != nil {
+		return err
+	}
+	r.Lastname2Set = presence.Lastname2 != nil
+	return nil
 }
 
 type SubjectID struct {
 	ID int `json:"id"`
 }
+
+// StudentFilter agrupa los filtros, el orden y la paginación de GET /v1/students.
+type StudentFilter struct {
+	CourseID        int
+	CurrentSemester int
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	Sort            string // "id" o "lastname"
+	Desc            bool
+	Limit           int
+	Offset          int
+}
+
+type StudentPage struct {
+	Data   []Alumno `json:"data"`
+	Total  int      `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+	Next   string   `json:"next,omitempty"` // URL de la siguiente página, vacía en la última
+}
+
+type StudentSearchResult struct {
+	Alumno
+	Score float64 `json:"score"` // relevancia entre 0 y 1
+}
+
+type RegisterAlumnResponse struct {
+	Message  string `json:"message"`
+	AlumnoID int    `json:"alumno_id"`
+}
+
+// AlumnIDRequest es el cuerpo de las lecturas antiguas que reciben el alumno en JSON.
+type AlumnIDRequest struct {
+	AlumnID int `json:"alumn_id"`
+}
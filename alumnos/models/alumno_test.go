@@ -0,0 +1,57 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUpdateAlumnRequestLastname2(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantSet   bool
+		wantValue *string
+		wantErr   string
+	}{
+		{"omitido", `{"name": "Ana"}`, false, nil, ""},
+		{"null", `{"lastname2": null}`, true, nil, ""},
+		{"vacío", `{"lastname2": ""}`, true, ptr(""), ""},
+		{"con valor", `{"lastname2": "López"}`, true, ptr("López"), ""},
+		{"campo desconocido", `{"lastname3": "López"}`, false, nil, `json: unknown field "lastname3"`},
+		{"tipo incorrecto", `{"lastname2": 7}`, false, nil, "cannot unmarshal number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Igual que decodeJSON en el paquete api
+			decoder := json.NewDecoder(bytes.NewReader([]byte(tt.body)))
+			decoder.DisallowUnknownFields()
+
+			var request UpdateAlumnRequest
+			err := decoder.Decode(&request)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decode() error = %v, se esperaba %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode(): %v", err)
+			}
+
+			if request.Lastname2Set != tt.wantSet {
+				t.Errorf("Lastname2Set = %v, se esperaba %v", request.Lastname2Set, tt.wantSet)
+			}
+			if (request.Lastname2 == nil) != (tt.wantValue == nil) ||
+				(request.Lastname2 != nil && *request.Lastname2 != *tt.wantValue) {
+				t.Errorf("Lastname2 = %v, se esperaba %v", request.Lastname2, tt.wantValue)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -8,10 +8,13 @@ type CalificacionParcial struct {
 }
 
 type MateriaCalificaciones struct {
-	SubjectID   int                   `json:"subject_id"`
-	SubjectName string                `json:"subject_name"`
-	Parciales   []CalificacionParcial `json:"parciales"`
-	Promedio    float64               `json:"promedio"` // Promedio de la materia
+	SubjectID     int                   `json:"subject_id"`
+	SubjectName   string                `json:"subject_name"`
+	Periodo       *string               `json:"periodo,omitempty"`        // Periodo en que se cursó (p. ej. 2025A)
+	PeriodoInicio *time.Time            `json:"periodo_inicio,omitempty"` // Fecha de inicio del periodo
+	PeriodoFin    *time.Time            `json:"periodo_fin,omitempty"`    // Fecha de fin del periodo
+	Parciales     []CalificacionParcial `json:"parciales"`
+	Promedio      float64               `json:"promedio"` // Promedio de la materia
 }
 
 type SemestreCalificaciones struct {
@@ -33,6 +36,13 @@ type Subject struct {
 	Coins int    `json:"coins"`
 }
 
+// SubjectRequest usa punteros para que el mismo tipo sirva al crear y al actualizar.
+type SubjectRequest struct {
+	Key   *string `json:"key"`
+	Name  *string `json:"name"`
+	Coins *int    `json:"coins"`
+}
+
 type PendingGrade struct {
 	SubjectID     int    `json:"subject_id"`
 	SubjectName   string `json:"subject_name"`
@@ -47,6 +57,7 @@ type SemesterCourse struct {
 	SemesterName string    `json:"semester_name"`
 	SubjectID    int       `json:"subject_id"`
 	SubjectName  string    `json:"subject_name"`
+	PeriodCode   *string   `json:"period_code,omitempty"`
 	FinalGrade   *float64  `json:"final_grade,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -78,3 +89,77 @@ type SemesterGrades struct {
 	CreatedAt          time.Time `json:"created_at"`           // Fecha de creación del registro
 	UpdatedAt          time.Time `json:"updated_at"`           // Última fecha de actualización
 }
+
+// CurriculumResult resume los cambios aplicados al cargar un plan de estudios.
+type CurriculumResult struct {
+	Course    string `json:"course"`
+	Version   string `json:"version"`
+	Inserted  int    `json:"inserted"`
+	Updated   int    `json:"updated"`
+	Retired   int    `json:"retired"`
+	Unchanged int    `json:"unchanged"`
+	Skipped   int    `json:"skipped"` // materias que un administrador modificó
+}
+
+// SeedResult resume los cambios aplicados por un seed de catálogo.
+type SeedResult struct {
+	Table     string `json:"table"`
+	Inserted  int    `json:"inserted"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+}
+
+type EnrollSemesterRequest struct {
+	AlumnoID   int    `json:"alumno_id"`
+	SemesterID int    `json:"semester_id"`
+	SubjectIDs []int  `json:"subject_ids"`
+	PeriodCode string `json:"period_code,omitempty"` // opcional, por defecto el periodo vigente
+}
+
+// DuplicateEnrollment es un grupo de inscripciones repetidas de un alumno en
+// la misma materia y semestre; KeptID es la que se conserva al unirlas.
+type DuplicateEnrollment struct {
+	AlumnID        int    `json:"alumn_id"`
+	SemesterID     int    `json:"semester_id"`
+	SubjectID      int    `json:"subject_id"`
+	SubjectKey     string `json:"subject_key"`
+	SubjectName    string `json:"subject_name"`
+	KeptID         int    `json:"kept_id"`
+	MergedIDs      []int  `json:"merged_ids"`
+	GradeConflicts int    `json:"grade_conflicts"` // parciales con calificaciones distintas entre las copias
+	// Parciales que se conservarían y no cumplen las restricciones de
+	// partial_grades: calificaciones fuera de 0 a 10 y números de parcial
+	// distintos de 1 y 2. El grupo no se une hasta corregirlos a mano.
+	InvalidGrades   int `json:"invalid_grades"`
+	InvalidPartials int `json:"invalid_partials"`
+}
+
+// NeedsManualFix indica que el grupo tiene parciales inválidos y que unirlo
+// obligaría a cambiar o descartar calificaciones.
+func (d DuplicateEnrollment) NeedsManualFix() bool {
+	return d.InvalidGrades > 0 || d.InvalidPartials > 0
+}
+
+type PartialGradeRequest struct {
+	SemesterCourseID int      `json:"semester_course_id"`
+	PartialNumber    int      `json:"partial_number"`
+	Grade            *float64 `json:"grade"` // puntero para distinguir un 0 de un campo omitido
+}
+
+type CalificacionesAgrupadasRequest struct {
+	AlumnoID int `json:"alumno_id"`
+}
+
+type CalificacionesAgrupadasResponse struct {
+	PromedioFinal float64                  `json:"promedio_final"`
+	Semestres     []SemestreCalificaciones `json:"semestres"`
+}
+
+type PendingGradesResponse struct {
+	Message       string         `json:"message"`
+	PendingGrades []PendingGrade `json:"pending_grades"`
+}
+
+type CourseIDRequest struct {
+	CourseID int `json:"course_id"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// HealthResponse es la respuesta de /healthz: el proceso está vivo.
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse es la respuesta de /readyz con el resultado de cada verificación.
+type ReadinessResponse struct {
+	Status string          `json:"status"` // "ready" o "not_ready"
+	Checks ReadinessChecks `json:"checks"`
+	Pool   PoolStats       `json:"pool"`
+}
+
+type ReadinessChecks struct {
+	Database   CheckResult `json:"database"`
+	Migrations CheckResult `json:"migrations"`
+	Seeds      CheckResult `json:"seeds"`
+}
+
+type CheckResult struct {
+	Status    string   `json:"status"` // "ok" o "failed"
+	LatencyMS float64  `json:"latency_ms"`
+	Message   string   `json:"message,omitempty"`
+	Pending   []string `json:"pending,omitempty"` // migraciones sin aplicar
+}
+
+// PoolStats resume el estado del pool de conexiones de pgx.
+type PoolStats struct {
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+	AcquireCount      int64 `json:"acquire_count"`
+	EmptyAcquireCount int64 `json:"empty_acquire_count"`
+	CanceledAcquires  int64 `json:"canceled_acquire_count"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// StoredResponse es la respuesta guardada para una Idempotency-Key.
+// StatusCode 0 indica que la solicitud original todavía no termina.
+type StoredResponse struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
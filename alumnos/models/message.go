@@ -0,0 +1,6 @@
+package models
+
+// MessageResponse es la respuesta de las operaciones que solo confirman el resultado.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
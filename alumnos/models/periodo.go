@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AcademicPeriod es un periodo del calendario escolar (p. ej. 2025A).
+type AcademicPeriod struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AcademicPeriodRequest struct {
+	Code      string `json:"code"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD
+	EndDate   string `json:"end_date"`   // YYYY-MM-DD
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type Teacher struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Lastname1 string    `json:"lastname1"`
+	Lastname2 string    `json:"lastname2,omitempty"`
+	Email     string    `json:"email"`
+	Password  string    `json:"-"` // hash bcrypt, nunca se serializa
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TeacherSubjectRequest struct {
+	TeacherID int `json:"teacher_id"`
+	SubjectID int `json:"subject_id"`
+}
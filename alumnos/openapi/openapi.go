@@ -0,0 +1,33 @@
+// Package openapi publica la especificación OpenAPI 3 de la API y la página
+// que la documenta. La verificación contra los handlers está en openapitest.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docs []byte
+
+// Spec devuelve el documento OpenAPI embebido.
+func Spec() []byte {
+	return spec
+}
+
+// ServeSpec responde GET /openapi.json.
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(spec)
+}
+
+// ServeDocs responde GET /docs con una página que renderiza la especificación.
+func ServeDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docs)
+}
@@ -0,0 +1,277 @@
+// Package openapitest verifica que la especificación OpenAPI siga coincidiendo
+// con las rutas y los modelos de los handlers. Solo lo importan las pruebas,
+// así que no se compila en el servidor.
+package openapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation describe lo que un handler realmente recibe y devuelve. Request y
+// Response son valores de ejemplo del tipo Go (p. ej. models.Alumno{}); nil
+// indica que la ruta no tiene cuerpo JSON.
+type Operation struct {
+	Pattern  string // patrón de http.ServeMux, "METHOD /ruta"
+	Request  any
+	Response any // cuerpo de la respuesta exitosa (2xx)
+}
+
+type document struct {
+	Paths      map[string]map[string]operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	RequestBody *struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]mediaType `json:"content"`
+	} `json:"responses"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	Required   []string           `json:"required"`
+}
+
+// Check compara la especificación con las operaciones registradas y con los
+// esquemas con nombre indicados. Devuelve una línea por cada diferencia; una
+// lista vacía significa que la especificación está al día.
+func Check(specJSON []byte, operations []Operation, named map[string]any) ([]string, error) {
+	var doc document
+	if err := json.Unmarshal(specJSON, &doc); err != nil {
+		return nil, fmt.Errorf("error al leer la especificación: %w", err)
+	}
+
+	c := checker{schemas: doc.Components.Schemas}
+	registered := make(map[string]bool, len(operations))
+
+	for _, op := range operations {
+		method, path, found := strings.Cut(op.Pattern, " ")
+		if !found {
+			c.addf("%s: el patrón no indica el método", op.Pattern)
+			continue
+		}
+		key := strings.ToLower(method) + " " + path
+		registered[key] = true
+
+		specOp, ok := doc.Paths[path][strings.ToLower(method)]
+		if !ok {
+			c.addf("%s: la ruta no está documentada", op.Pattern)
+			continue
+		}
+
+		var requestSchema *schema
+		if specOp.RequestBody != nil {
+			requestSchema = specOp.RequestBody.Content["application/json"].Schema
+		}
+		c.compareBody(op.Pattern+" (request)", requestSchema, op.Request)
+		c.compareBody(op.Pattern+" (response)", successSchema(specOp), op.Response)
+	}
+
+	// Operaciones documentadas que ya no existen en el router
+	for path, methods := range doc.Paths {
+		for method := range methods {
+			if !registered[method+" "+path] {
+				c.addf("%s %s: está documentada pero no está registrada", strings.ToUpper(method), path)
+			}
+		}
+	}
+
+	for name, model := range named {
+		s, ok := c.schemas[name]
+		if !ok {
+			c.addf("components.schemas.%s: no existe", name)
+			continue
+		}
+		c.compare("components.schemas."+name, s, reflect.TypeOf(model))
+	}
+
+	sort.Strings(c.problems)
+	return c.problems, nil
+}
+
+// successSchema devuelve el esquema JSON de la primera respuesta 2xx.
+func successSchema(op operation) *schema {
+	codes := make([]int, 0, len(op.Responses))
+	for code := range op.Responses {
+		if n, err := strconv.Atoi(code); err == nil && n >= 200 && n < 300 {
+			codes = append(codes, n)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	sort.Ints(codes)
+	return op.Responses[strconv.Itoa(codes[0])].Content["application/json"].Schema
+}
+
+type checker struct {
+	schemas  map[string]*schema
+	problems []string
+	visiting map[string]bool
+}
+
+func (c *checker) addf(format string, args ...any) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) compareBody(where string, s *schema, model any) {
+	switch {
+	case s == nil && model == nil:
+	case s == nil:
+		c.addf("%s: el handler usa %T pero la especificación no documenta el cuerpo", where, model)
+	case model == nil:
+		c.addf("%s: la especificación documenta un cuerpo que el handler no usa", where)
+	default:
+		c.compare(where, s, reflect.TypeOf(model))
+	}
+}
+
+func (c *checker) compare(where string, s *schema, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		resolved, ok := c.schemas[name]
+		if !ok {
+			c.addf("%s: referencia a un esquema inexistente %s", where, s.Ref)
+			return
+		}
+		// Evitar ciclos en esquemas recursivos
+		key := name + "|" + t.String()
+		if c.visiting == nil {
+			c.visiting = make(map[string]bool)
+		}
+		if c.visiting[key] {
+			return
+		}
+		c.visiting[key] = true
+		defer delete(c.visiting, key)
+
+		c.compare(where, resolved, t)
+		return
+	}
+
+	want := jsonType(t)
+	if want == "" {
+		return // interface{}: cualquier valor es válido
+	}
+	if s.Type != want {
+		c.addf("%s: la especificación dice %q pero el tipo Go %s se codifica como %q", where, s.Type, t, want)
+		return
+	}
+
+	switch want {
+	case "array":
+		if s.Items == nil {
+			c.addf("%s: el arreglo no define items", where)
+			return
+		}
+		c.compare(where+"[]", s.Items, t.Elem())
+	case "object":
+		if t.Kind() != reflect.Struct {
+			return // mapas: las propiedades son libres
+		}
+		fields := jsonFields(t)
+		for name, field := range fields {
+			prop, ok := s.Properties[name]
+			if !ok {
+				c.addf("%s: falta la propiedad %q en la especificación", where, name)
+				continue
+			}
+			c.compare(where+"."+name, prop, field)
+		}
+		for name := range s.Properties {
+			if _, ok := fields[name]; !ok {
+				c.addf("%s: la propiedad %q no existe en %s", where, name, t)
+			}
+		}
+		for _, name := range s.Required {
+			if _, ok := s.Properties[name]; !ok {
+				c.addf("%s: el campo requerido %q no es una propiedad", where, name)
+			}
+		}
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonType devuelve el tipo JSON con el que encoding/json codifica t.
+func jsonType(t reflect.Type) string {
+	if t == timeType {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return ""
+}
+
+// jsonFields devuelve los campos que encoding/json serializa, incluidos los
+// de structs embebidos, indexados por su nombre en JSON.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for embeddedName, embeddedType := range jsonFields(embedded) {
+					if _, ok := fields[embeddedName]; !ok {
+						fields[embeddedName] = embeddedType
+					}
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies son las redes de los proxies (nginx) cuyo X-Forwarded-For y
+// traceparent se aceptan. Sin proxies de confianza se usa siempre la dirección
+// de la conexión, porque cualquier cliente puede enviar esos headers.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies lee una lista de CIDR o IP separadas por comas.
+func ParseTrustedProxies(list string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, fmt.Errorf("dirección inválida %q", item)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("red inválida %q", item)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP devuelve la IP del cliente. Si la conexión viene de un proxy de
+// confianza, recorre X-Forwarded-For de derecha a izquierda y devuelve la
+// primera dirección que no es de un proxy; las entradas más a la izquierda
+// las escribe el cliente y no se usan.
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	host, remote, ok := t.fromProxy(r)
+	if !ok {
+		return host
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break // entrada falsificada o mal formada: usar el último salto válido
+		}
+		if !t.contains(addr) {
+			return addr.Unmap().String()
+		}
+		remote = addr
+	}
+	return remote.Unmap().String()
+}
+
+// FromProxy indica si la conexión viene de un proxy de confianza, es decir, si
+// se pueden creer los headers que agrega (X-Forwarded-For, traceparent).
+func (t TrustedProxies) FromProxy(r *http.Request) bool {
+	_, _, ok := t.fromProxy(r)
+	return ok
+}
+
+// fromProxy devuelve el host de la conexión, su dirección y si es de un proxy
+// de confianza.
+func (t TrustedProxies) fromProxy(r *http.Request) (string, netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil || !t.contains(remote) {
+		return host, netip.Addr{}, false
+	}
+	return host, remote, true
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"10.0.0.1", []string{"10.0.0.1/32"}, false},
+		{"172.16.5.9/12, ::1", []string{"172.16.0.0/12", "::1/128"}, false},
+		{" 10.0.0.0/8 ,", []string{"10.0.0.0/8"}, false},
+		{"nginx", nil, true},
+		{"10.0.0.0/40", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			proxies, err := ParseTrustedProxies(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrustedProxies(%q) error = %v, se esperaba error: %v", tt.list, err, tt.wantErr)
+			}
+			var got []string
+			for _, prefix := range proxies {
+				got = append(got, prefix.String())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseTrustedProxies(%q) = %v, se esperaba %v", tt.list, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseTrustedProxies(%q) = %v, se esperaba %v", tt.list, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		proxies   TrustedProxies
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{"sin proxies ignora el header", nil, "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"conexión que no es de un proxy", proxies, "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"detrás de nginx", proxies, "10.0.0.2:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"entrada falsificada a la izquierda", proxies, "10.0.0.2:5000", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"varios proxies", proxies, "10.0.0.2:5000", []string{"198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"varios headers", proxies, "10.0.0.2:5000", []string{"1.2.3.4", "198.51.100.1"}, "198.51.100.1"},
+		{"entrada mal formada", proxies, "10.0.0.2:5000", []string{"198.51.100.1, basura, 10.0.0.3"}, "10.0.0.3"},
+		{"sin header", proxies, "10.0.0.2:5000", nil, "10.0.0.2"},
+		{"IPv4 mapeada en IPv6", proxies, "[::ffff:10.0.0.2]:5000", []string{"::ffff:198.51.100.1"}, "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if got := tt.proxies.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromProxy(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		proxies TrustedProxies
+		remote  string
+		want    bool
+	}{
+		{"sin proxies", nil, "10.0.0.2:5000", false},
+		{"proxy de confianza", proxies, "10.0.0.2:5000", true},
+		{"cliente directo", proxies, "203.0.113.7:5000", false},
+		{"IPv4 mapeada en IPv6", proxies, "[::ffff:10.0.0.2]:5000", true},
+		{"dirección inválida", proxies, "nginx", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if got := tt.proxies.FromProxy(r); got != tt.want {
+				t.Errorf("FromProxy() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
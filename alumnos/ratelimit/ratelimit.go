@@ -0,0 +1,95 @@
+// Package ratelimit implementa límites de solicitudes con token bucket por
+// clave (IP del cliente o usuario autenticado).
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter reparte Burst fichas por clave y repone Rate fichas por segundo.
+// Cada solicitud consume una; sin fichas, la solicitud se rechaza. Un Limiter
+// nil o con Rate 0 no limita nada.
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow consume una ficha de key. Si no quedan, devuelve false y cuánto falta
+// para la siguiente, redondeado hacia arriba al segundo para Retry-After:
+// redondear hacia abajo invitaría al cliente a reintentar antes de tiempo.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l == nil || l.rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := (1 - b.tokens) / l.rate
+	return false, time.Duration(math.Ceil(wait)) * time.Second
+}
+
+// Run descarta periódicamente los buckets llenos hasta que ctx se cancela;
+// una clave que vuelve empieza con el bucket lleno, así que no cambia nada.
+func (l *Limiter) Run(ctx context.Context, interval time.Duration) {
+	if l == nil || l.rate <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.prune()
+		}
+	}
+}
+
+func (l *Limiter) prune() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
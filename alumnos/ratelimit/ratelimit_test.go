@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(1, 2)
+	l.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		key       string
+		wantOK    bool
+		wantRetry time.Duration
+	}{
+		{"primera ficha", 0, "a", true, 0},
+		{"segunda ficha", 0, "a", true, 0},
+		{"sin fichas", 0, "a", false, time.Second},
+		{"otra clave tiene su propio bucket", 0, "b", true, 0},
+		{"media ficha redondea hacia arriba", 500 * time.Millisecond, "a", false, time.Second},
+		{"ficha repuesta", 500 * time.Millisecond, "a", true, 0},
+		{"no acumula más que burst", time.Hour, "a", true, 0},
+		{"segunda tras la espera", 0, "a", true, 0},
+		{"burst agotado otra vez", 0, "a", false, time.Second},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		ok, retry := l.Allow(tt.key)
+		if ok != tt.wantOK || retry != tt.wantRetry {
+			t.Errorf("%s: Allow(%q) = (%v, %v), se esperaba (%v, %v)", tt.name, tt.key, ok, retry, tt.wantOK, tt.wantRetry)
+		}
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter *Limiter
+	}{
+		{"nil", nil},
+		{"rate 0", New(0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if ok, _ := tt.limiter.Allow("a"); !ok {
+					t.Fatalf("Allow() rechazó la solicitud %d", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterPrune(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(1, 2)
+	l.now = func() time.Time { return now }
+
+	l.Allow("lleno")
+	l.Allow("vacío")
+	l.Allow("vacío")
+	now = now.Add(time.Second)
+	l.prune()
+
+	if _, ok := l.buckets["lleno"]; ok {
+		t.Error("prune() conservó un bucket que ya se llenó")
+	}
+	if _, ok := l.buckets["vacío"]; !ok {
+		t.Error("prune() descartó un bucket sin llenar")
+	}
+}
@@ -1,51 +1,97 @@
 package repository
 
 import (
+	"alumnos/logging"
 	"alumnos/models"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrIncompleteSemesters indica que el alumno tiene semestres anteriores sin
+// promedio final y aún no puede inscribirse en el solicitado.
+var ErrIncompleteSemesters = errors.New("no se puede registrar el nuevo semestre porque hay semestres anteriores incompletos")
+
 type PgxStorage struct {
 	DbPool *pgxpool.Pool
+	Logger *slog.Logger
+}
+
+func NewPgxStorage(dbPool *pgxpool.Pool, logger *slog.Logger) *PgxStorage {
+	return &PgxStorage{DbPool: dbPool, Logger: logger}
 }
 
-func NewPgxStorage(dbPool *pgxpool.Pool) *PgxStorage {
-	return &PgxStorage{DbPool: dbPool}
+// log devuelve el logger de la solicitud en curso, o el del repositorio
+// cuando la llamada no viene de una solicitud (arranque, comandos).
+func (s *PgxStorage) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, s.Logger)
+}
+
+// activeAlumn devuelve pgx.ErrNoRows si el alumno no existe o fue eliminado.
+// Las consultas de calificaciones e inscripciones la usan para distinguir a un
+// alumno eliminado (404) de uno que aún no tiene registros (lista vacía).
+func (s *PgxStorage) activeAlumn(ctx context.Context, alumnID int) error {
+	var exists bool
+	err := s.DbPool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM alumn WHERE id = $1 AND deleted_at IS NULL)`, alumnID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error al verificar alumno %d: %w", alumnID, err)
+	}
+	if !exists {
+		return fmt.Errorf("alumno %d: %w", alumnID, pgx.ErrNoRows)
+	}
+	return nil
 }
 
 func (s *PgxStorage) RegisterAlumn(ctx context.Context, request models.RegisterAlumnRequest) (int, error) {
+	ctx = WithOperation(ctx, "RegisterAlumn")
 	tx, err := s.DbPool.Begin(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("error al iniciar transacción: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
+	// Las credenciales son opcionales; se guardan como NULL si no se envían.
+	// request.Password ya llega como hash bcrypt desde el handler.
+	var email, passwordHash *string
+	if request.Email != "" {
+		email, passwordHash = &request.Email, &request.Password
+	}
+
 	// Insertar al alumno en la tabla `alumn`
 	var alumnoID int
 	insertAlumnQuery := `
-		INSERT INTO alumn (name, lastname1, lastname2, course_id, current_semester)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO alumn (name, lastname1, lastname2, course_id, current_semester, email, password)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id;
 	`
 
 	err = tx.QueryRow(ctx, insertAlumnQuery,
 		request.Name, request.Lastname1, request.Lastname2,
-		request.CourseID, request.CurrentCourseID).Scan(&alumnoID)
+		request.CourseID, request.CurrentCourseID, email, passwordHash).Scan(&alumnoID)
 	if err != nil {
 		return 0, fmt.Errorf("error al registrar alumno: %w", err)
 	}
 
+	periodID, err := resolvePeriodID(ctx, tx, request.PeriodCode)
+	if err != nil {
+		return 0, err
+	}
+
 	// Asignar materias del curso al alumno en el semestre actual
 	insertSubjectQuery := `
-		INSERT INTO semester_course (alumn_id, semester_id, subject_id)
-		VALUES ($1, $2, $3);
+		INSERT INTO semester_course (alumn_id, semester_id, subject_id, period_id)
+		VALUES ($1, $2, $3, $4);
 	`
 
 	for _, subject := range request.Subjects {
-		_, err = tx.Exec(ctx, insertSubjectQuery, alumnoID, request.CurrentCourseID, subject.ID)
+		_, err = tx.Exec(ctx, insertSubjectQuery, alumnoID, request.CurrentCourseID, subject.ID, periodID)
 		if err != nil {
 			return 0, fmt.Errorf("error al asignar materia (ID: %d): %w", subject.ID, err)
 		}
@@ -60,13 +106,19 @@ func (s *PgxStorage) RegisterAlumn(ctx context.Context, request models.RegisterA
 }
 
 func (s *PgxStorage) GetSemesterCoursesByAlumnId(ctx context.Context, alumnID int) ([]models.SemesterCourse, error) {
+	ctx = WithOperation(ctx, "GetSemesterCoursesByAlumnId")
+	if err := s.activeAlumn(ctx, alumnID); err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT sc.id, sc.alumn_id, sc.semester_id, cs.name AS semester_name, sc.subject_id, ah.name AS subject_name, sc.final_grade, sc.created_at, sc.updated_at
+		SELECT sc.id, sc.alumn_id, sc.semester_id, cs.name AS semester_name, sc.subject_id, ah.name AS subject_name, ap.code AS period_code, sc.final_grade, sc.created_at, sc.updated_at
 		FROM semester_course sc
 		LEFT JOIN academyc_history ah ON sc.subject_id = ah.id
 		LEFT JOIN cat_semesters cs ON sc.semester_id = cs.id
+		LEFT JOIN academic_periods ap ON sc.period_id = ap.id
 		JOIN alumn a ON sc.alumn_id = a.id
-		WHERE a.id = $1 AND sc.semester_id = a.current_semester;
+		WHERE a.id = $1 AND sc.semester_id = a.current_semester AND a.deleted_at IS NULL;
 	`
 
 	rows, err := s.DbPool.Query(ctx, query, alumnID)
@@ -78,7 +130,7 @@ func (s *PgxStorage) GetSemesterCoursesByAlumnId(ctx context.Context, alumnID in
 	var courses []models.SemesterCourse
 	for rows.Next() {
 		var c models.SemesterCourse
-		err := rows.Scan(&c.ID, &c.AlumnID, &c.SemesterID, &c.SemesterName, &c.SubjectID, &c.SubjectName, &c.FinalGrade, &c.CreatedAt, &c.UpdatedAt)
+		err := rows.Scan(&c.ID, &c.AlumnID, &c.SemesterID, &c.SemesterName, &c.SubjectID, &c.SubjectName, &c.PeriodCode, &c.FinalGrade, &c.CreatedAt, &c.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("error al escanear semester_course: %w", err)
 		}
@@ -114,7 +166,8 @@ func (s *PgxStorage) GetSemesterCoursesByAlumnId(ctx context.Context, alumnID in
 	return courses, nil
 }
 
-func (s *PgxStorage) RegistrarEnSemestreConMaterias(ctx context.Context, alumnoID, semesterID int, subjectIDs []int) error {
+func (s *PgxStorage) RegistrarEnSemestreConMaterias(ctx context.Context, alumnoID, semesterID int, subjectIDs []int, periodCode string) error {
+	ctx = WithOperation(ctx, "RegistrarEnSemestreConMaterias")
 	// Validar si los semestres anteriores han sido completados
 	completionQuery := `
 		SELECT COUNT(*)
@@ -130,7 +183,7 @@ func (s *PgxStorage) RegistrarEnSemestreConMaterias(ctx context.Context, alumnoI
 	}
 
 	if incompleteSemesters > 0 {
-		return fmt.Errorf("no se puede registrar el nuevo semestre porque hay semestres anteriores incompletos")
+		return ErrIncompleteSemesters
 	}
 
 	// Iniciar la transacción para registrar las materias
@@ -140,15 +193,28 @@ func (s *PgxStorage) RegistrarEnSemestreConMaterias(ctx context.Context, alumnoI
 	}
 	defer tx.Rollback(ctx)
 
+	periodID, err := resolvePeriodID(ctx, tx, periodCode)
+	if err != nil {
+		return err
+	}
+
+	// Rechazar la solicitud completa si alguna materia ya está inscrita
+	if err := checkNotEnrolled(ctx, tx, alumnoID, semesterID, subjectIDs); err != nil {
+		return err
+	}
+
 	// Registrar materias en el semestre
 	query := `
-		INSERT INTO semester_course (alumn_id, semester_id, subject_id)
-		VALUES ($1, $2, $3);
+		INSERT INTO semester_course (alumn_id, semester_id, subject_id, period_id)
+		VALUES ($1, $2, $3, $4);
 	`
 
 	for _, subjectID := range subjectIDs {
-		_, err = tx.Exec(ctx, query, alumnoID, semesterID, subjectID)
+		_, err = tx.Exec(ctx, query, alumnoID, semesterID, subjectID, periodID)
 		if err != nil {
+			if conflict := s.enrollmentConflict(ctx, err, alumnoID, semesterID, subjectIDs); conflict != nil {
+				return conflict
+			}
 			return fmt.Errorf("error al registrar materia %d: %w", subjectID, err)
 		}
 	}
@@ -174,28 +240,43 @@ func (s *PgxStorage) RegistrarEnSemestreConMaterias(ctx context.Context, alumnoI
 }
 
 func (s *PgxStorage) RegistrarCalificacionParcial(ctx context.Context, semesterCourseID, partialNumber int, grade float64) error {
+	ctx = WithOperation(ctx, "RegistrarCalificacionParcial")
+	// No se califica a un alumno eliminado
 	query := `
 		INSERT INTO partial_grades (semester_course_id, partial_number, grade)
-		VALUES ($1, $2, $3)
+		SELECT sc.id, $2, $3
+		FROM semester_course sc
+		JOIN alumn a ON a.id = sc.alumn_id
+		WHERE sc.id = $1 AND a.deleted_at IS NULL
 		ON CONFLICT (semester_course_id, partial_number)
 		DO UPDATE SET grade = $3;
 	`
 
-	_, err := s.DbPool.Exec(ctx, query, semesterCourseID, partialNumber, grade)
+	tag, err := s.DbPool.Exec(ctx, query, semesterCourseID, partialNumber, grade)
 	if err != nil {
 		return fmt.Errorf("error al registrar o actualizar calificación del parcial %d: %w", partialNumber, err)
 	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error al registrar calificación del parcial %d: %w", partialNumber, pgx.ErrNoRows)
+	}
 
 	return nil
 }
 
 func (s *PgxStorage) GenerarCalificacionesAgrupadasPorSemestre(ctx context.Context, alumnoID int) ([]models.SemestreCalificaciones, float64, error) {
+	ctx = WithOperation(ctx, "GenerarCalificacionesAgrupadasPorSemestre")
+	if err := s.activeAlumn(ctx, alumnoID); err != nil {
+		return nil, 0, err
+	}
+
 	query := `
-		SELECT sc.semester_id, cs.name AS semester_name, sc.subject_id, ah.name AS subject_name, pg.partial_number, pg.grade
+		SELECT sc.semester_id, cs.name AS semester_name, sc.subject_id, ah.name AS subject_name, ap.code AS period_code, ap.start_date, ap.end_date, pg.partial_number, pg.grade
 		FROM semester_course sc
 		JOIN partial_grades pg ON sc.id = pg.semester_course_id
+		JOIN alumn a ON a.id = sc.alumn_id AND a.deleted_at IS NULL
 		LEFT JOIN cat_semesters cs ON sc.semester_id = cs.id
 		LEFT JOIN academyc_history ah ON sc.subject_id = ah.id
+		LEFT JOIN academic_periods ap ON sc.period_id = ap.id
 		WHERE sc.alumn_id = $1
 		ORDER BY sc.semester_id, sc.subject_id, pg.partial_number;
 	`
@@ -206,187 +287,111 @@ func (s *PgxStorage) GenerarCalificacionesAgrupadasPorSemestre(ctx context.Conte
 	}
 	defer rows.Close()
 
-	var semestres []models.SemestreCalificaciones
-	calificacionTotal := 0.0
-	numCalificaciones := 0
-
-	// Mapa para agrupar calificaciones por semestre
-	calificacionesPorSemestre := make(map[int]*models.SemestreCalificaciones)
-
+	var calificaciones []calificacionRow
 	for rows.Next() {
-		var semesterID, subjectID, partialNumber int
-		var semesterName, subjectName string
-		var grade float64
-
-		if err := rows.Scan(&semesterID, &semesterName, &subjectID, &subjectName, &partialNumber, &grade); err != nil {
+		var c calificacionRow
+		if err := rows.Scan(&c.SemesterID, &c.SemesterName, &c.SubjectID, &c.SubjectName, &c.PeriodCode, &c.PeriodStart, &c.PeriodEnd, &c.PartialNumber, &c.Grade); err != nil {
 			return nil, 0, fmt.Errorf("error al procesar filas: %w", err)
 		}
+		calificaciones = append(calificaciones, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error al procesar filas: %w", err)
+	}
 
+	semestres, promedioFinal := agruparCalificaciones(calificaciones)
+	return semestres, promedioFinal, nil
+}
+
+// calificacionRow es una fila de la consulta de GenerarCalificacionesAgrupadasPorSemestre.
+type calificacionRow struct {
+	SemesterID    int
+	SemesterName  string
+	SubjectID     int
+	SubjectName   string
+	PeriodCode    *string
+	PeriodStart   *time.Time
+	PeriodEnd     *time.Time
+	PartialNumber int
+	Grade         float64
+}
+
+// agruparCalificaciones agrupa los parciales por semestre y materia en el
+// orden en que llegan y calcula los promedios de cada materia, de cada
+// semestre y el general. Sin calificaciones, el promedio es 0.
+func agruparCalificaciones(calificaciones []calificacionRow) ([]models.SemestreCalificaciones, float64) {
+	var semestres []models.SemestreCalificaciones
+	semestreIndex := make(map[int]int)
+	calificacionTotal := 0.0
+
+	for _, c := range calificaciones {
 		// Verificar si el semestre ya fue agregado
-		if _, exists := calificacionesPorSemestre[semesterID]; !exists {
-			calificacionesPorSemestre[semesterID] = &models.SemestreCalificaciones{
-				SemesterID:   semesterID,
-				SemesterName: semesterName,
+		i, exists := semestreIndex[c.SemesterID]
+		if !exists {
+			i = len(semestres)
+			semestreIndex[c.SemesterID] = i
+			semestres = append(semestres, models.SemestreCalificaciones{
+				SemesterID:   c.SemesterID,
+				SemesterName: c.SemesterName,
 				Materias:     []models.MateriaCalificaciones{},
-			}
+			})
 		}
+		semestre := &semestres[i]
 
-		// Verificar si la materia ya fue agregada al semestre
-		materias := calificacionesPorSemestre[semesterID].Materias
-		var materia *models.MateriaCalificaciones
-		for i := range materias {
-			if materias[i].SubjectID == subjectID {
-				materia = &materias[i]
-				break
-			}
-		}
-		if materia == nil {
-			// Agregar nueva materia si no existe
-			materia = &models.MateriaCalificaciones{
-				SubjectID:   subjectID,
-				SubjectName: subjectName,
-				Parciales:   []models.CalificacionParcial{},
-				Promedio:    0,
-			}
-			calificacionesPorSemestre[semesterID].Materias = append(calificacionesPorSemestre[semesterID].Materias, *materia)
+		// Verificar si la materia ya fue agregada al semestre; se modifica por
+		// índice para no agregar el parcial a una copia
+		j := slices.IndexFunc(semestre.Materias, func(m models.MateriaCalificaciones) bool {
+			return m.SubjectID == c.SubjectID
+		})
+		if j < 0 {
+			j = len(semestre.Materias)
+			semestre.Materias = append(semestre.Materias, models.MateriaCalificaciones{
+				SubjectID:     c.SubjectID,
+				SubjectName:   c.SubjectName,
+				Periodo:       c.PeriodCode,
+				PeriodoInicio: c.PeriodStart,
+				PeriodoFin:    c.PeriodEnd,
+				Parciales:     []models.CalificacionParcial{},
+			})
 		}
-
-		// Agregar el parcial a la materia
+		materia := &semestre.Materias[j]
 		materia.Parciales = append(materia.Parciales, models.CalificacionParcial{
-			PartialNumber: partialNumber,
-			Grade:         grade,
+			PartialNumber: c.PartialNumber,
+			Grade:         c.Grade,
 		})
 
-		// Actualizar totales
-		calificacionTotal += grade
-		numCalificaciones++
+		calificacionTotal += c.Grade
 	}
 
 	// Calcular promedios por materia y semestre
-	for _, semestre := range calificacionesPorSemestre {
-		var totalSemestre float64
-		var numMaterias int
-
-		for i, materia := range semestre.Materias {
+	for i := range semestres {
+		semestre := &semestres[i]
+		totalSemestre := 0.0
+		for j := range semestre.Materias {
+			materia := &semestre.Materias[j]
 			totalMateria := 0.0
 			for _, parcial := range materia.Parciales {
 				totalMateria += parcial.Grade
 			}
-			materia.Promedio = totalMateria / float64(len(materia.Parciales))
-			semestre.Materias[i] = materia
-
+			materia.Promedio = average(totalMateria, len(materia.Parciales))
 			totalSemestre += materia.Promedio
-			numMaterias++
 		}
-
-		// Calcular promedio del semestre
-		semestre.Promedio = totalSemestre / float64(numMaterias)
-		semestres = append(semestres, *semestre)
-	}
-
-	// Calcular promedio general
-	promedioFinal := calificacionTotal / float64(numCalificaciones)
-
-	return semestres, promedioFinal, nil
-}
-
-func (s *PgxStorage) SeedCatCourses(ctx context.Context) error {
-	query := `
-		INSERT INTO cat_courses (name) 
-		VALUES ($1)
-		ON CONFLICT DO NOTHING;
-	`
-
-	_, err := s.DbPool.Exec(ctx, query, "INGENIERIA EN COMPUTACION")
-	if err != nil {
-		return fmt.Errorf("error al hacer seed de cat_courses: %w", err)
+		semestre.Promedio = average(totalSemestre, len(semestre.Materias))
 	}
 
-	return nil
+	return semestres, average(calificacionTotal, len(calificaciones))
 }
 
-func (s *PgxStorage) SeedAcademycHistory(ctx context.Context) error {
-	query := `
-		INSERT INTO academyc_history (course_id, key, name, coins)
-		VALUES
-		(1, 'LINC01', 'ALGEBRA LINEAL', 7),
-		(1, 'LINC02', 'ALGEBRA SUPERIOR', 7),
-		(1, 'LINC03', 'CALCULO I', 7),
-		(1, 'LINC04', 'CALCULO II', 7),
-		(1, 'LINC05', 'CALCULO III', 7),
-		(1, 'LINC06', 'COMUNICACION Y RELACIONES HUMANAS', 7),
-		(1, 'LINC07', 'ECUACIONES DIFERENCIALES', 7),
-		(1, 'LINC08', 'EL INGENIERO Y SU ENTORNO SOCIOECONOMICO', 7),
-		(1, 'LINC09', 'ELECTROMAGNETISMO', 7),
-		(1, 'LINC10', 'EPISTEMOLOGIA', 7),
-		(1, 'LINC11', 'FISICA', 7),
-		(1, 'LINC12', 'GEOMETRIA ANALITICA', 7),
-		(1, 'LINC13', 'MATEMATICAS DISCRETAS', 7),
-		(1, 'LINC14', 'PROBABILIDAD Y ESTADISTICA', 7),
-		(1, 'LINC15', 'PROGRAMACION I', 7),
-		(1, 'LINC29', 'QUIMICA', 7),
-		(1, 'LMU209', 'INGLES 5', 6),
-		(1, 'LMU306', 'INGLES 6', 6),
-		(1, 'LMU404', 'INGLES 7', 6),
-		(1, 'LMU505', 'INGLES 8', 6),
-		(1, 'LINC16', 'ADMINISTRACION DE PROYECTOS INFORMATICOS', 7),
-		(1, 'LINC17', 'ADMINISTRACION DE RECURSOS INFORMATICOS', 7),
-		(1, 'LINC18', 'ARQUITECTURA DE COMPUTADORAS', 7),
-		(1, 'LINC19', 'ARQUITECTURA DE REDES', 5),
-		(1, 'LINC20', 'BASES DE DATOS I', 7),
-		(1, 'LINC21', 'BASES DE DATOS II', 5),
-		(1, 'LINC22', 'CIRCUITOS ELECTRICOS Y ELECTRONICOS', 10),
-		(1, 'LINC23', 'COMPILADORES', 7),
-		(1, 'LINC24', 'ENSAMBLADORES', 7),
-		(1, 'LINC25', 'GRAFICACION COMPUTACIONAL', 5),
-		(1, 'LINC26', 'INGENIERIA DE SOFTWARE I', 7),
-		(1, 'LINC27', 'INGENIERIA DE SOFTWARE II', 7),
-		(1, 'LINC28', 'INTELIGENCIA ARTIFICIAL', 7),
-		(1, 'LINC30', 'METODOS ESTADISTICOS', 7),
-		(1, 'LINC31', 'METODOS NUMERICOS', 5),
-		(1, 'LINC32', 'PARADIGMAS DE PROGRAMACION I', 5),
-		(1, 'LINC33', 'PARADIGMAS DE PROGRAMACION II', 5),
-		(1, 'LINC34', 'PROCESAMIENTO DE IMAGENES DIGITALES', 7),
-		(1, 'LINC35', 'PROGRAMACION II', 7),
-		(1, 'LINC36', 'PROTOCOLOS DE COMUNICACION DE DATOS', 7),
-		(1, 'LINC37', 'ROBOTICA', 7),
-		(1, 'LINC38', 'SEGURIDAD DE LA INFORMACION', 7),
-		(1, 'LINC39', 'SISTEMAS ANALOGICOS', 7),
-		(1, 'LINC40', 'SISTEMAS DIGITALES', 7),
-		(1, 'LINC41', 'SISTEMAS OPERATIVOS', 7),
-		(1, 'LINC42', 'TRANSMISION DE DATOS', 7),
-		(1, 'L41004', 'INVESTIGACION DE OPERACIONES', 7),
-		(1, 'LINC43', 'CIENCIA DE LOS DATOS', 5),
-		(1, 'LINC44', 'ETICA PROFESIONAL Y SUSTENTABILIDAD', 6),
-		(1, 'LINC45', 'GESTION DE PROYECTOS DE INVESTIGACION', 4),
-		(1, 'LINC46', 'PROYECTO INTEGRAL DE COMUNICACION DE DATOS', 5),
-		(1, 'LINC47', 'PROYECTO INTEGRAL DE INGENIERIA DE SOFTWARE', 5),
-		(1, 'LINC48', 'SISTEMAS EMBEBIDOS', 6),
-		(1, 'LINC49', 'TECNOLOGIAS COMPUTACIONALES I', 5),
-		(1, 'LINC50', 'TECNOLOGIAS COMPUTACIONALES II', 5),
-		(1, 'LINC51', 'INTEGRATIVA PROFESIONAL', 8),
-		(1, 'LINC52', 'PRACTICA PROFESIONAL', 30),
-		(1, 'LINC53', 'ANALISIS Y DISEÑO DE REDES', 5),
-		(1, 'LINC54', 'COMPUTING IN INDUSTRY', 5),
-		(1, 'LINC55', 'GESTION DE REDES', 5),
-		(1, 'LINC56', 'INTERACCION HOMBRE-MAQUINA', 5),
-		(1, 'LINC57', 'RECONOCIMIENTO DE PATRONES', 5),
-		(1, 'LINC58', 'SISTEMAS INTERACTIVOS', 5),
-		(1, 'LINC59', 'TECNOLOGIAS EMERGENTES', 5),
-		(1, 'LINC60', 'TOPICOS DE TECNOLOGIAS DE DATOS', 5),
-		(1, 'LINC61', 'VISION ARTIFICIAL', 5)
-		ON CONFLICT DO NOTHING;
-	`
-
-	_, err := s.DbPool.Exec(ctx, query)
-	if err != nil {
-		return fmt.Errorf("error al insertar materias en academyc_history: %w", err)
+// average evita dividir entre cero (NaN no se puede codificar en JSON).
+func average(total float64, count int) float64 {
+	if count == 0 {
+		return 0
 	}
-
-	return nil
+	return total / float64(count)
 }
 
 func (s *PgxStorage) GetCourses(ctx context.Context) ([]models.Course, error) {
+	ctx = WithOperation(ctx, "GetCourses")
 	query := `SELECT id, name FROM cat_courses`
 	rows, err := s.DbPool.Query(ctx, query)
 	if err != nil {
@@ -407,7 +412,8 @@ func (s *PgxStorage) GetCourses(ctx context.Context) ([]models.Course, error) {
 }
 
 func (s *PgxStorage) GetSubjectsByCourse(ctx context.Context, courseID int) ([]models.Subject, error) {
-	query := `SELECT id, key, name, coins FROM academyc_history WHERE course_id = $1`
+	ctx = WithOperation(ctx, "GetSubjectsByCourse")
+	query := `SELECT id, key, name, coins FROM academyc_history WHERE course_id = $1 AND retired_at IS NULL ORDER BY key`
 	rows, err := s.DbPool.Query(ctx, query, courseID)
 	if err != nil {
 		return nil, fmt.Errorf("error al obtener materias: %w", err)
@@ -426,27 +432,62 @@ func (s *PgxStorage) GetSubjectsByCourse(ctx context.Context, courseID int) ([]m
 	return subjects, nil
 }
 
-func (s *PgxStorage) GetStudents(ctx context.Context) ([]models.Alumno, error) {
-	query := `
+func (s *PgxStorage) GetStudents(ctx context.Context, filter models.StudentFilter) ([]models.Alumno, int, error) {
+	ctx = WithOperation(ctx, "GetStudents")
+	// Construir el WHERE dinámico con parámetros posicionales
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.CourseID > 0 {
+		addCondition("course_id = $%d", filter.CourseID)
+	}
+	if filter.CurrentSemester > 0 {
+		addCondition("current_semester = $%d", filter.CurrentSemester)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("created_at < $%d", *filter.CreatedTo)
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Total de alumnos que cumplen los filtros, sin paginar
+	var total int
+	countQuery := "SELECT COUNT(*) FROM alumn " + where
+	if err := s.DbPool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error al contar alumnos: %w", err)
+	}
+
+	query := fmt.Sprintf(`
 		SELECT 
 			id, 
 			name, 
 			lastname1, 
-			lastname2, 
+			COALESCE(lastname2, ''), 
 			course_id, 
 			current_semester, 
 			created_at, 
 			updated_at
 		FROM alumn
-	`
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, where, studentsOrderBy(filter), len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
 
-	rows, err := s.DbPool.Query(ctx, query)
+	rows, err := s.DbPool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener alumnos: %w", err)
+		return nil, 0, fmt.Errorf("error al obtener alumnos: %w", err)
 	}
 	defer rows.Close()
 
-	var alumnos []models.Alumno
+	alumnos := []models.Alumno{}
 	for rows.Next() {
 		var alumno models.Alumno
 		if err := rows.Scan(
@@ -459,38 +500,92 @@ func (s *PgxStorage) GetStudents(ctx context.Context) ([]models.Alumno, error) {
 			&alumno.CreatedAt,
 			&alumno.UpdatedAt,
 		); err != nil {
-			return nil, fmt.Errorf("error al escanear alumnos: %w", err)
+			return nil, 0, fmt.Errorf("error al escanear alumnos: %w", err)
 		}
 		alumnos = append(alumnos, alumno)
 	}
 
-	return alumnos, nil
+	return alumnos, total, nil
 }
 
-func (s *PgxStorage) SeedCatSemesters(ctx context.Context) error {
+// studentsOrderBy arma el ORDER BY del listado de alumnos. Solo admite
+// columnas conocidas y id desempata para que la paginación sea estable. El
+// orden por apellido recorre idx_alumn_lastname (lastname2 NULLS FIRST) hacia
+// adelante o hacia atrás, así que los NULLS de cada dirección deben coincidir.
+func studentsOrderBy(filter models.StudentFilter) string {
+	if filter.Sort == "lastname" {
+		if filter.Desc {
+			return "lastname1 DESC, lastname2 DESC NULLS LAST, name DESC, id DESC"
+		}
+		return "lastname1 ASC, lastname2 ASC NULLS FIRST, name ASC, id ASC"
+	}
+	if filter.Desc {
+		return "id DESC"
+	}
+	return "id ASC"
+}
+
+// SeedCatSemesters hace upsert de los semestres por id e informa qué cambió.
+func (s *PgxStorage) SeedCatSemesters(ctx context.Context) (models.SeedResult, error) {
+	ctx = WithOperation(ctx, "SeedCatSemesters")
+	result := models.SeedResult{Table: "cat_semesters"}
+
+	names := []string{
+		"Primer Semestre",
+		"Segundo Semestre",
+		"Tercer Semestre",
+		"Cuarto Semestre",
+		"Quinto Semestre",
+		"Sexto Semestre",
+		"Séptimo Semestre",
+		"Octavo Semestre",
+	}
+
 	query := `
 		INSERT INTO cat_semesters (id, name)
-		VALUES 
-		(1, 'Primer Semestre'),
-		(2, 'Segundo Semestre'),
-		(3, 'Tercer Semestre'),
-		(4, 'Cuarto Semestre'),
-		(5, 'Quinto Semestre'),
-		(6, 'Sexto Semestre'),
-		(7, 'Séptimo Semestre'),
-		(8, 'Octavo Semestre')
-		ON CONFLICT (id) DO NOTHING;
+		SELECT * FROM unnest($1::int[], $2::text[])
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name, updated_at = CURRENT_TIMESTAMP
+		WHERE cat_semesters.name IS DISTINCT FROM EXCLUDED.name
+		RETURNING (xmax = 0) AS inserted;
 	`
 
-	_, err := s.DbPool.Exec(ctx, query)
+	ids := make([]int, len(names))
+	for i := range names {
+		ids[i] = i + 1
+	}
+
+	rows, err := s.DbPool.Query(ctx, query, ids, names)
 	if err != nil {
-		return fmt.Errorf("error al insertar datos en cat_semesters: %w", err)
+		return result, fmt.Errorf("error al insertar datos en cat_semesters: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	for rows.Next() {
+		var inserted bool
+		if err := rows.Scan(&inserted); err != nil {
+			return result, fmt.Errorf("error al insertar datos en cat_semesters: %w", err)
+		}
+		if inserted {
+			result.Inserted++
+		} else {
+			result.Updated++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("error al insertar datos en cat_semesters: %w", err)
+	}
+	result.Unchanged = len(names) - result.Inserted - result.Updated
+
+	return result, nil
 }
 
 func (s *PgxStorage) GetPendingGradesForCurrentSemester(ctx context.Context, alumnID int) ([]models.PendingGrade, error) {
+	ctx = WithOperation(ctx, "GetPendingGradesForCurrentSemester")
+	if err := s.activeAlumn(ctx, alumnID); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT 
 			sc.subject_id,
@@ -504,7 +599,7 @@ func (s *PgxStorage) GetPendingGradesForCurrentSemester(ctx context.Context, alu
 		  AND sc.semester_id = (
 			  SELECT current_semester
 			  FROM alumn
-			  WHERE id = $1
+			  WHERE id = $1 AND deleted_at IS NULL
 		  )
 		  AND pg.grade IS NULL
 		ORDER BY sc.semester_id, sc.subject_id, pg.partial_number;
@@ -529,15 +624,18 @@ func (s *PgxStorage) GetPendingGradesForCurrentSemester(ctx context.Context, alu
 }
 
 func (s *PgxStorage) GetAlumnIDBySemesterCourseID(ctx context.Context, semesterCourseID int, alumnID *int) error {
+	ctx = WithOperation(ctx, "GetAlumnIDBySemesterCourseID")
 	query := `
 		SELECT sc.alumn_id
 		FROM semester_course sc
-		WHERE sc.id = $1;
+		JOIN alumn a ON a.id = sc.alumn_id
+		WHERE sc.id = $1 AND a.deleted_at IS NULL;
 	`
 	return s.DbPool.QueryRow(ctx, query, semesterCourseID).Scan(alumnID)
 }
 
 func (s *PgxStorage) GetCatSemesters(ctx context.Context) ([]models.CatSemester, error) {
+	ctx = WithOperation(ctx, "GetCatSemesters")
 	query := `
 		SELECT 
 			id, 
@@ -572,9 +670,15 @@ func (s *PgxStorage) GetCatSemesters(ctx context.Context) ([]models.CatSemester,
 }
 
 func (s *PgxStorage) GetCompletedSemesters(ctx context.Context, alumnID int) ([]models.SemesterGrades, error) {
+	ctx = WithOperation(ctx, "GetCompletedSemesters")
+	if err := s.activeAlumn(ctx, alumnID); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT sg.id, sg.alumn_id, sg.semester_id, sg.final_semester_grade, sg.created_at, sg.updated_at
 		FROM semester_grades sg
+		JOIN alumn a ON a.id = sg.alumn_id AND a.deleted_at IS NULL
 		WHERE sg.alumn_id = $1 AND sg.final_semester_grade IS NOT NULL;
 	`
 
@@ -596,3 +700,169 @@ func (s *PgxStorage) GetCompletedSemesters(ctx context.Context, alumnID int) ([]
 
 	return completedSemesters, nil
 }
+
+func (s *PgxStorage) GetAlumnCredentialsByEmail(ctx context.Context, email string) (*models.AlumnCredentials, error) {
+	ctx = WithOperation(ctx, "GetAlumnCredentialsByEmail")
+	query := `
+		SELECT id, email, password
+		FROM alumn
+		WHERE email = $1 AND password IS NOT NULL AND deleted_at IS NULL;
+	`
+
+	var c models.AlumnCredentials
+	if err := s.DbPool.QueryRow(ctx, query, email).Scan(&c.ID, &c.Email, &c.Password); err != nil {
+		return nil, fmt.Errorf("error al obtener credenciales del alumno: %w", err)
+	}
+
+	return &c, nil
+}
+
+// SearchStudents busca alumnos por nombre completo, ignorando acentos y
+// mayúsculas y tolerando errores de escritura ("gar lop" encuentra a "García
+// López"). El filtro <% (word_similarity de pg_trgm) se aplica sobre la misma
+// expresión que idx_alumn_search_name para que use el índice. Los resultados
+// se ordenan por similitud.
+func (s *PgxStorage) SearchStudents(ctx context.Context, search string, limit int) ([]models.StudentSearchResult, error) {
+	ctx = WithOperation(ctx, "SearchStudents")
+	query := `
+		SELECT
+			id,
+			name,
+			lastname1,
+			COALESCE(lastname2, ''),
+			course_id,
+			current_semester,
+			created_at,
+			updated_at,
+			word_similarity(lower(public.unaccent('public.unaccent', $1)), alumn_search_name(name, lastname1, lastname2)) AS score
+		FROM alumn
+		WHERE lower(public.unaccent('public.unaccent', $1)) <% alumn_search_name(name, lastname1, lastname2)
+			AND deleted_at IS NULL
+		ORDER BY score DESC, lastname1, id
+		LIMIT $2;
+	`
+
+	rows, err := s.DbPool.Query(ctx, query, search, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar alumnos: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.StudentSearchResult{}
+	for rows.Next() {
+		var result models.StudentSearchResult
+		if err := rows.Scan(
+			&result.ID,
+			&result.Name,
+			&result.Lastname1,
+			&result.Lastname2,
+			&result.CourseID,
+			&result.CurrentCourseID,
+			&result.CreatedAt,
+			&result.UpdatedAt,
+			&result.Score,
+		); err != nil {
+			return nil, fmt.Errorf("error al escanear resultados de búsqueda: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (s *PgxStorage) GetStudentByID(ctx context.Context, alumnID int) (*models.Alumno, error) {
+	ctx = WithOperation(ctx, "GetStudentByID")
+	query := `
+		SELECT 
+			id, 
+			name, 
+			lastname1, 
+			COALESCE(lastname2, ''), 
+			course_id, 
+			current_semester, 
+			created_at, 
+			updated_at
+		FROM alumn
+		WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	var alumno models.Alumno
+	err := s.DbPool.QueryRow(ctx, query, alumnID).Scan(
+		&alumno.ID,
+		&alumno.Name,
+		&alumno.Lastname1,
+		&alumno.Lastname2,
+		&alumno.CourseID,
+		&alumno.CurrentCourseID,
+		&alumno.CreatedAt,
+		&alumno.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener alumno %d: %w", alumnID, err)
+	}
+
+	return &alumno, nil
+}
+
+// UpdateStudent aplica solo los campos enviados en la solicitud.
+func (s *PgxStorage) UpdateStudent(ctx context.Context, alumnID int, request models.UpdateAlumnRequest) (*models.Alumno, error) {
+	ctx = WithOperation(ctx, "UpdateStudent")
+	query := `
+		UPDATE alumn
+		SET name = COALESCE($2, name),
+			lastname1 = COALESCE($3, lastname1),
+			lastname2 = CASE WHEN $5::boolean THEN NULLIF($4, '') ELSE lastname2 END,
+			course_id = COALESCE($6, course_id),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	tag, err := s.DbPool.Exec(ctx, query, alumnID, request.Name, request.Lastname1, request.Lastname2, request.Lastname2Set, request.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("error al actualizar alumno %d: %w", alumnID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("error al actualizar alumno %d: %w", alumnID, pgx.ErrNoRows)
+	}
+
+	return s.GetStudentByID(ctx, alumnID)
+}
+
+// SoftDeleteStudent marca al alumno como eliminado sin borrar su historial.
+func (s *PgxStorage) SoftDeleteStudent(ctx context.Context, alumnID int) error {
+	ctx = WithOperation(ctx, "SoftDeleteStudent")
+	query := `
+		UPDATE alumn
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL;
+	`
+
+	tag, err := s.DbPool.Exec(ctx, query, alumnID)
+	if err != nil {
+		return fmt.Errorf("error al eliminar alumno %d: %w", alumnID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error al eliminar alumno %d: %w", alumnID, pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (s *PgxStorage) RestoreStudent(ctx context.Context, alumnID int) error {
+	ctx = WithOperation(ctx, "RestoreStudent")
+	query := `
+		UPDATE alumn
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL;
+	`
+
+	tag, err := s.DbPool.Exec(ctx, query, alumnID)
+	if err != nil {
+		return fmt.Errorf("error al restaurar alumno %d: %w", alumnID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error al restaurar alumno %d: %w", alumnID, pgx.ErrNoRows)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"alumnos/db/dbtest"
+	"alumnos/models"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAgruparCalificaciones(t *testing.T) {
+	row := func(semesterID, subjectID, partial int, grade float64) calificacionRow {
+		return calificacionRow{
+			SemesterID: semesterID, SemesterName: "Semestre",
+			SubjectID: subjectID, SubjectName: "Materia",
+			PartialNumber: partial, Grade: grade,
+		}
+	}
+	materia := func(subjectID int, promedio float64, grades ...float64) models.MateriaCalificaciones {
+		m := models.MateriaCalificaciones{SubjectID: subjectID, SubjectName: "Materia", Promedio: promedio, Parciales: []models.CalificacionParcial{}}
+		for i, grade := range grades {
+			m.Parciales = append(m.Parciales, models.CalificacionParcial{PartialNumber: i + 1, Grade: grade})
+		}
+		return m
+	}
+
+	tests := []struct {
+		name          string
+		rows          []calificacionRow
+		wantSemestres []models.SemestreCalificaciones
+		wantPromedio  float64
+	}{
+		{
+			name:          "sin calificaciones",
+			rows:          nil,
+			wantSemestres: nil,
+			wantPromedio:  0,
+		},
+		{
+			name: "conserva el primer parcial de cada materia",
+			rows: []calificacionRow{row(1, 10, 1, 6), row(1, 10, 2, 8), row(1, 11, 1, 10)},
+			wantSemestres: []models.SemestreCalificaciones{
+				{SemesterID: 1, SemesterName: "Semestre", Promedio: 8.5, Materias: []models.MateriaCalificaciones{
+					materia(10, 7, 6, 8),
+					materia(11, 10, 10),
+				}},
+			},
+			wantPromedio: 8,
+		},
+		{
+			name: "varios semestres en orden",
+			rows: []calificacionRow{row(1, 10, 1, 9), row(2, 20, 1, 7), row(2, 20, 2, 9)},
+			wantSemestres: []models.SemestreCalificaciones{
+				{SemesterID: 1, SemesterName: "Semestre", Promedio: 9, Materias: []models.MateriaCalificaciones{materia(10, 9, 9)}},
+				{SemesterID: 2, SemesterName: "Semestre", Promedio: 8, Materias: []models.MateriaCalificaciones{materia(20, 8, 7, 9)}},
+			},
+			wantPromedio: 25.0 / 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			semestres, promedio := agruparCalificaciones(tt.rows)
+			if !reflect.DeepEqual(semestres, tt.wantSemestres) {
+				t.Errorf("semestres = %+v, se esperaba %+v", semestres, tt.wantSemestres)
+			}
+			if promedio != tt.wantPromedio {
+				t.Errorf("promedio = %v, se esperaba %v", promedio, tt.wantPromedio)
+			}
+			// NaN o Inf harían fallar la respuesta
+			if _, err := json.Marshal(models.CalificacionesAgrupadasResponse{PromedioFinal: promedio, Semestres: semestres}); err != nil {
+				t.Errorf("la respuesta no se puede codificar: %v", err)
+			}
+		})
+	}
+}
+
+func TestAverage(t *testing.T) {
+	tests := []struct {
+		total float64
+		count int
+		want  float64
+	}{
+		{0, 0, 0},
+		{15, 2, 7.5},
+		{9, 1, 9},
+	}
+	for _, tt := range tests {
+		if got := average(tt.total, tt.count); got != tt.want {
+			t.Errorf("average(%v, %d) = %v, se esperaba %v", tt.total, tt.count, got, tt.want)
+		}
+	}
+}
+
+// El orden por apellido, en ambas direcciones, se lee de idx_alumn_lastname
+// sin un Sort aparte.
+func TestStudentsOrderByUsesIndex(t *testing.T) {
+	pool := dbtest.Migrated(t)
+	ctx := context.Background()
+
+	for _, desc := range []bool{false, true} {
+		orderBy := studentsOrderBy(models.StudentFilter{Sort: "lastname", Desc: desc})
+		t.Run(orderBy, func(t *testing.T) {
+			tx, err := pool.Begin(ctx)
+			if err != nil {
+				t.Fatalf("error al iniciar la transacción: %v", err)
+			}
+			defer tx.Rollback(ctx)
+			// La tabla está vacía: sin esto el planificador prefiere leerla completa
+			if _, err := tx.Exec(ctx, "SET LOCAL enable_seqscan = off"); err != nil {
+				t.Fatalf("error al desactivar seqscan: %v", err)
+			}
+
+			rows, err := tx.Query(ctx, "EXPLAIN SELECT id FROM alumn WHERE deleted_at IS NULL ORDER BY "+orderBy+" LIMIT 20")
+			if err != nil {
+				t.Fatalf("EXPLAIN: %v", err)
+			}
+			var plan []string
+			for rows.Next() {
+				var line string
+				if err := rows.Scan(&line); err != nil {
+					t.Fatalf("error al leer el plan: %v", err)
+				}
+				plan = append(plan, line)
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatalf("EXPLAIN: %v", err)
+			}
+
+			text := strings.Join(plan, "\n")
+			if !strings.Contains(text, "idx_alumn_lastname") || strings.Contains(text, "Sort") {
+				t.Errorf("el plan no recorre idx_alumn_lastname en orden:\n%s", text)
+			}
+		})
+	}
+}
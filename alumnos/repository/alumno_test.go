@@ -0,0 +1,275 @@
+package repository_test
+
+import (
+	"alumnos/db/dbtest"
+	"alumnos/models"
+	"alumnos/repository"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func newStorage(t *testing.T) (*repository.PgxStorage, *pgxpool.Pool) {
+	t.Helper()
+	pool := dbtest.Migrated(t)
+	return repository.NewPgxStorage(pool, slog.New(slog.NewTextHandler(io.Discard, nil))), pool
+}
+
+// exec ejecuta una sentencia de preparación y falla la prueba si no se puede.
+func exec(t *testing.T, pool *pgxpool.Pool, sql string, args ...any) {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), sql, args...); err != nil {
+		t.Fatalf("error al preparar datos: %v", err)
+	}
+}
+
+func TestSearchStudents(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+
+	exec(t, pool, `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO alumn (name, lastname1, lastname2, course_id) VALUES
+			('Ana', 'García', 'López', 1),
+			('José', 'Martínez', NULL, 1),
+			('Lucía', 'Garza', 'Núñez', 1),
+			('Pedro', 'Gómez', 'Ruiz', 1);
+		UPDATE alumn SET deleted_at = CURRENT_TIMESTAMP WHERE name = 'Pedro';
+	`)
+
+	tests := []struct {
+		search string
+		want   []string // apellido paterno, en orden
+	}{
+		{"garcia lopez", []string{"García"}},
+		{"GARCÍA", []string{"García"}},
+		{"jose martinez", []string{"Martínez"}},
+		{"martines", []string{"Martínez"}}, // error de escritura
+		{"nunez", []string{"Garza"}},
+		{"gomez ruiz", nil}, // borrado
+		{"zzz", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			results, err := storage.SearchStudents(ctx, tt.search, 10)
+			if err != nil {
+				t.Fatalf("SearchStudents: %v", err)
+			}
+			var got []string
+			for _, result := range results {
+				got = append(got, result.Lastname1)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchStudents(%q) = %v, se esperaba %v", tt.search, got, tt.want)
+			}
+		})
+	}
+}
+
+// El filtro de SearchStudents debe poder usar el índice de trigramas en lugar
+// de recorrer alumn.
+func TestSearchStudentsUsesIndex(t *testing.T) {
+	_, pool := newStorage(t)
+	ctx := context.Background()
+
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SET enable_seqscan = off"); err != nil {
+		t.Fatalf("SET enable_seqscan: %v", err)
+	}
+	defer conn.Exec(ctx, "RESET enable_seqscan")
+
+	rows, err := conn.Query(ctx, `
+		EXPLAIN SELECT id FROM alumn
+		WHERE lower(public.unaccent('public.unaccent', $1)) <% alumn_search_name(name, lastname1, lastname2)
+			AND deleted_at IS NULL
+	`, "garcia")
+	if err != nil {
+		t.Fatalf("EXPLAIN: %v", err)
+	}
+	plan, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		t.Fatalf("EXPLAIN: %v", err)
+	}
+	if !strings.Contains(strings.Join(plan, "\n"), "idx_alumn_search_name") {
+		t.Errorf("el plan no usa idx_alumn_search_name:\n%s", strings.Join(plan, "\n"))
+	}
+}
+
+func TestUpdateStudentLastname2(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO alumn (id, name, lastname1, lastname2, course_id) VALUES (1, 'Ana', 'García', 'López', 1);
+	`)
+
+	name, empty := "Ana María", ""
+	tests := []struct {
+		name    string
+		request models.UpdateAlumnRequest
+		want    string
+	}{
+		{"omitido conserva el valor", models.UpdateAlumnRequest{Name: &name}, "López"},
+		{"null lo borra", models.UpdateAlumnRequest{Lastname2Set: true}, ""},
+		{"se vuelve a asignar", models.UpdateAlumnRequest{Lastname2: ptr("Ruiz"), Lastname2Set: true}, "Ruiz"},
+		{"cadena vacía lo borra", models.UpdateAlumnRequest{Lastname2: &empty, Lastname2Set: true}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alumno, err := storage.UpdateStudent(ctx, 1, tt.request)
+			if err != nil {
+				t.Fatalf("UpdateStudent: %v", err)
+			}
+			if alumno.Lastname2 != tt.want {
+				t.Errorf("lastname2 = %q, se esperaba %q", alumno.Lastname2, tt.want)
+			}
+		})
+	}
+}
+
+// Las consultas de calificaciones e inscripciones tratan a un alumno eliminado
+// como inexistente.
+func TestDeletedStudentNotFound(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO cat_semesters (id, name) VALUES (1, 'Primer semestre');
+		INSERT INTO academyc_history (id, course_id, key, name) VALUES (1, 1, 'MAT1', 'Matemáticas');
+		INSERT INTO alumn (id, name, lastname1, course_id, current_semester) VALUES (1, 'Ana', 'García', 1, 1);
+		INSERT INTO semester_course (id, alumn_id, semester_id, subject_id) VALUES (1, 1, 1, 1);
+		INSERT INTO partial_grades (semester_course_id, partial_number, grade) VALUES (1, 1, 8);
+	`)
+	if err := storage.SoftDeleteStudent(ctx, 1); err != nil {
+		t.Fatalf("SoftDeleteStudent: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"materias del semestre", func() error {
+			_, err := storage.GetSemesterCoursesByAlumnId(ctx, 1)
+			return err
+		}},
+		{"calificaciones agrupadas", func() error {
+			_, _, err := storage.GenerarCalificacionesAgrupadasPorSemestre(ctx, 1)
+			return err
+		}},
+		{"calificaciones pendientes", func() error {
+			_, err := storage.GetPendingGradesForCurrentSemester(ctx, 1)
+			return err
+		}},
+		{"semestres completados", func() error {
+			_, err := storage.GetCompletedSemesters(ctx, 1)
+			return err
+		}},
+		{"alumno de la inscripción", func() error {
+			var alumnID int
+			return storage.GetAlumnIDBySemesterCourseID(ctx, 1, &alumnID)
+		}},
+		{"registrar parcial", func() error {
+			return storage.RegistrarCalificacionParcial(ctx, 1, 2, 9)
+		}},
+		{"carrera del alumno", func() error {
+			_, err := storage.GetAlumnCourseID(ctx, 1)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, pgx.ErrNoRows) {
+				t.Errorf("error = %v, se esperaba pgx.ErrNoRows", err)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestRegistrarEnSemestreIncompleteSemesters(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		INSERT INTO cat_semesters (id, name) VALUES (1, 'Primer semestre'), (2, 'Segundo semestre');
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO academyc_history (id, course_id, key, name) VALUES (1, 1, 'MAT1', 'Matemáticas I'), (2, 1, 'MAT2', 'Matemáticas II');
+		INSERT INTO alumn (id, name, lastname1, course_id, current_semester) VALUES (1, 'Ana', 'García', 1, 1);
+		INSERT INTO semester_course (alumn_id, semester_id, subject_id) VALUES (1, 1, 1);
+		INSERT INTO academic_periods (code, start_date, end_date) VALUES ('2025A', CURRENT_DATE - 30, CURRENT_DATE + 30);
+	`)
+
+	err := storage.RegistrarEnSemestreConMaterias(ctx, 1, 2, []int{2}, "2025A")
+	if !errors.Is(err, repository.ErrIncompleteSemesters) {
+		t.Fatalf("error = %v, se esperaba ErrIncompleteSemesters", err)
+	}
+
+	// Con las calificaciones del primer semestre ya puede inscribirse
+	exec(t, pool, `
+		INSERT INTO partial_grades (semester_course_id, partial_number, grade)
+		SELECT id, n, 8 FROM semester_course, generate_series(1, 2) AS n;
+	`)
+	if err := storage.RegistrarEnSemestreConMaterias(ctx, 1, 2, []int{2}, "2025A"); err != nil {
+		t.Fatalf("RegistrarEnSemestreConMaterias: %v", err)
+	}
+}
+
+func TestGetStudentsOrder(t *testing.T) {
+	storage, pool := newStorage(t)
+	exec(t, pool, `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO alumn (id, name, lastname1, lastname2, course_id) VALUES
+			(1, 'Ana', 'García', 'López', 1),
+			(2, 'Luis', 'García', NULL, 1),
+			(3, 'Eva', 'Díaz', 'Ruiz', 1),
+			(4, 'Ana', 'García', 'López', 1);
+	`)
+
+	tests := []struct {
+		name   string
+		filter models.StudentFilter
+		want   []int
+	}{
+		{"por id", models.StudentFilter{Sort: "id"}, []int{1, 2, 3, 4}},
+		{"por id descendente", models.StudentFilter{Sort: "id", Desc: true}, []int{4, 3, 2, 1}},
+		// Sin segundo apellido va antes en orden ascendente y después en el inverso
+		{"por apellido", models.StudentFilter{Sort: "lastname"}, []int{3, 2, 1, 4}},
+		{"por apellido descendente", models.StudentFilter{Sort: "lastname", Desc: true}, []int{4, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Limit = 10
+			alumnos, total, err := storage.GetStudents(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("GetStudents: %v", err)
+			}
+			if total != len(tt.want) {
+				t.Errorf("total = %d, se esperaba %d", total, len(tt.want))
+			}
+			var ids []int
+			for _, alumno := range alumnos {
+				ids = append(ids, alumno.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("ids = %v, se esperaba %v", ids, tt.want)
+			}
+		})
+	}
+}
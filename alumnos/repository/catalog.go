@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// Consultas de catálogo que usa el paquete validation.
+
+func (s *PgxStorage) SemesterExists(ctx context.Context, semesterID int) (bool, error) {
+	ctx = WithOperation(ctx, "SemesterExists")
+	var exists bool
+	err := s.DbPool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM cat_semesters WHERE id = $1)`, semesterID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error al verificar semestre %d: %w", semesterID, err)
+	}
+	return exists, nil
+}
+
+func (s *PgxStorage) CourseExists(ctx context.Context, courseID int) (bool, error) {
+	ctx = WithOperation(ctx, "CourseExists")
+	var exists bool
+	err := s.DbPool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM cat_courses WHERE id = $1)`, courseID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error al verificar curso %d: %w", courseID, err)
+	}
+	return exists, nil
+}
+
+func (s *PgxStorage) SubjectsNotInCourse(ctx context.Context, courseID int, subjectIDs []int) ([]int, error) {
+	ctx = WithOperation(ctx, "SubjectsNotInCourse")
+	query := `
+		SELECT id FROM unnest($2::int[]) AS id
+		EXCEPT
+		SELECT id FROM academyc_history WHERE course_id = $1 AND retired_at IS NULL;
+	`
+
+	rows, err := s.DbPool.Query(ctx, query, courseID, subjectIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar materias del curso %d: %w", courseID, err)
+	}
+	defer rows.Close()
+
+	var outside []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error al verificar materias del curso %d: %w", courseID, err)
+		}
+		outside = append(outside, id)
+	}
+
+	return outside, rows.Err()
+}
+
+// GetAlumnCourseID devuelve la carrera de un alumno activo.
+func (s *PgxStorage) GetAlumnCourseID(ctx context.Context, alumnID int) (int, error) {
+	ctx = WithOperation(ctx, "GetAlumnCourseID")
+	var courseID int
+	err := s.DbPool.QueryRow(ctx, `SELECT course_id FROM alumn WHERE id = $1 AND deleted_at IS NULL`, alumnID).Scan(&courseID)
+	if err != nil {
+		return 0, fmt.Errorf("error al obtener carrera del alumno %d: %w", alumnID, err)
+	}
+	return courseID, nil
+}
+
+// CatalogCounts cuenta las filas de los catálogos que cargan los seeds al
+// arrancar; la verificación de disponibilidad exige que no estén vacíos.
+func (s *PgxStorage) CatalogCounts(ctx context.Context) (semesters, courses int, err error) {
+	ctx = WithOperation(ctx, "CatalogCounts")
+	err = s.DbPool.QueryRow(ctx, `
+		SELECT (SELECT count(*) FROM cat_semesters), (SELECT count(*) FROM cat_courses)
+	`).Scan(&semesters, &courses)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al contar catálogos: %w", err)
+	}
+	return semesters, courses, nil
+}
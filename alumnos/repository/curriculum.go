@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"alumnos/curriculum"
+	"alumnos/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ApplyCurriculum sincroniza una carrera y sus materias con el plan de
+// estudios: inserta las materias nuevas, actualiza las que cambiaron y da de
+// baja las que ya no aparecen. Solo toca materias que vienen del plan
+// (academyc_history.curriculum_version no nulo); las que creó, editó o dio de
+// baja un administrador se respetan y se cuentan en Skipped. Una materia sin
+// origen que ya coincide con el plan se adopta como del plan.
+func (s *PgxStorage) ApplyCurriculum(ctx context.Context, c curriculum.Curriculum) (models.CurriculumResult, error) {
+	ctx = WithOperation(ctx, "ApplyCurriculum")
+	result := models.CurriculumResult{Course: c.Course, Version: c.Version}
+
+	tx, err := s.DbPool.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Upsert de la carrera por su nombre (uq_cat_courses_name)
+	var courseID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO cat_courses (name, curriculum_version)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE
+		SET curriculum_version = EXCLUDED.curriculum_version,
+			updated_at = CASE
+				WHEN cat_courses.curriculum_version IS DISTINCT FROM EXCLUDED.curriculum_version THEN CURRENT_TIMESTAMP
+				ELSE cat_courses.updated_at
+			END
+		RETURNING id;
+	`, c.Course, c.Version).Scan(&courseID)
+	if err != nil {
+		return result, fmt.Errorf("error al registrar carrera %s: %w", c.Course, err)
+	}
+
+	keys := make([]string, 0, len(c.Subjects))
+	for _, subject := range c.Subjects {
+		keys = append(keys, subject.Key)
+
+		var (
+			id      int
+			name    string
+			coins   int
+			retired bool
+			version *string
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT id, name, COALESCE(coins, 0), retired_at IS NOT NULL, curriculum_version
+			FROM academyc_history
+			WHERE course_id = $1 AND key = $2
+			FOR UPDATE;
+		`, courseID, subject.Key).Scan(&id, &name, &coins, &retired, &version)
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO academyc_history (course_id, key, name, coins, curriculum_version)
+				VALUES ($1, $2, $3, $4, $5);
+			`, courseID, subject.Key, subject.Name, subject.Coins, c.Version)
+			if err != nil {
+				return result, fmt.Errorf("error al aplicar materia %s de %s: %w", subject.Key, c.Course, err)
+			}
+			result.Inserted++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("error al aplicar materia %s de %s: %w", subject.Key, c.Course, err)
+		}
+
+		matches := name == subject.Name && coins == subject.Coins && !retired
+		switch {
+		case version == nil && !matches:
+			// La creó o la modificó un administrador
+			result.Skipped++
+			continue
+		case matches:
+			result.Unchanged++
+			if version != nil && *version == c.Version {
+				continue
+			}
+		default:
+			result.Updated++
+		}
+
+		// A las filas sin cambios solo se les registra la versión del plan
+		_, err = tx.Exec(ctx, `
+			UPDATE academyc_history
+			SET name = $2,
+				coins = $3,
+				retired_at = NULL,
+				curriculum_version = $4,
+				updated_at = CASE WHEN $5::boolean THEN updated_at ELSE CURRENT_TIMESTAMP END
+			WHERE id = $1;
+		`, id, subject.Name, subject.Coins, c.Version, matches)
+		if err != nil {
+			return result, fmt.Errorf("error al aplicar materia %s de %s: %w", subject.Key, c.Course, err)
+		}
+	}
+
+	// Dar de baja lo que ya no está en el plan; el historial de los alumnos se conserva
+	rows, err := tx.Query(ctx, `
+		UPDATE academyc_history
+		SET retired_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE course_id = $1 AND retired_at IS NULL AND curriculum_version IS NOT NULL
+			AND NOT (key = ANY($2))
+		RETURNING key;
+	`, courseID, keys)
+	if err != nil {
+		return result, fmt.Errorf("error al dar de baja materias de %s: %w", c.Course, err)
+	}
+	retired, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return result, fmt.Errorf("error al dar de baja materias de %s: %w", c.Course, err)
+	}
+	result.Retired = len(retired)
+	if len(retired) > 0 {
+		s.log(ctx).Info("materias dadas de baja por el plan de estudios",
+			"course", c.Course, "version", c.Version, "keys", retired)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"alumnos/curriculum"
+	"alumnos/models"
+	"context"
+	"testing"
+)
+
+func TestApplyCurriculum(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+
+	plan := curriculum.Curriculum{
+		Version: "2024",
+		Course:  "Ingeniería en Computación",
+		Subjects: []curriculum.Subject{
+			{Key: "MAT1", Name: "Matemáticas I", Coins: 8},
+			{Key: "PRG1", Name: "Programación I", Coins: 10},
+			{Key: "FIS1", Name: "Física I", Coins: 8},
+			{Key: "QUI1", Name: "Química", Coins: 6},
+		},
+	}
+
+	// Primera carga
+	result, err := storage.ApplyCurriculum(ctx, plan)
+	if err != nil {
+		t.Fatalf("ApplyCurriculum: %v", err)
+	}
+	if want := (models.CurriculumResult{Course: plan.Course, Version: "2024", Inserted: 4}); result != want {
+		t.Fatalf("primera carga = %+v, se esperaba %+v", result, want)
+	}
+
+	var courseID, prgID, fisID int
+	row := pool.QueryRow(ctx, `
+		SELECT course_id,
+			(SELECT id FROM academyc_history WHERE key = 'PRG1'),
+			(SELECT id FROM academyc_history WHERE key = 'FIS1')
+		FROM academyc_history WHERE key = 'MAT1'
+	`)
+	if err := row.Scan(&courseID, &prgID, &fisID); err != nil {
+		t.Fatalf("error al leer materias: %v", err)
+	}
+
+	// Cambios de un administrador entre cargas
+	key, name, coins := "TAL1", "Taller de titulación", 4
+	if _, err := storage.CreateSubject(ctx, courseID, models.SubjectRequest{Key: &key, Name: &name, Coins: &coins}); err != nil {
+		t.Fatalf("CreateSubject: %v", err)
+	}
+	renamed := "Programación estructurada"
+	if _, err := storage.UpdateSubject(ctx, prgID, models.SubjectRequest{Name: &renamed}); err != nil {
+		t.Fatalf("UpdateSubject: %v", err)
+	}
+	if err := storage.RetireSubject(ctx, fisID); err != nil {
+		t.Fatalf("RetireSubject: %v", err)
+	}
+
+	// Una nueva versión del plan quita QUI1 y cambia MAT1
+	plan.Version = "2025"
+	plan.Subjects = []curriculum.Subject{
+		{Key: "MAT1", Name: "Matemáticas I", Coins: 9},
+		{Key: "PRG1", Name: "Programación I", Coins: 10},
+		{Key: "FIS1", Name: "Física I", Coins: 8},
+	}
+	result, err = storage.ApplyCurriculum(ctx, plan)
+	if err != nil {
+		t.Fatalf("ApplyCurriculum: %v", err)
+	}
+	if want := (models.CurriculumResult{Course: plan.Course, Version: "2025", Updated: 1, Retired: 1, Skipped: 2}); result != want {
+		t.Errorf("segunda carga = %+v, se esperaba %+v", result, want)
+	}
+
+	// Aplicar el mismo plan otra vez no cambia nada
+	result, err = storage.ApplyCurriculum(ctx, plan)
+	if err != nil {
+		t.Fatalf("ApplyCurriculum: %v", err)
+	}
+	if want := (models.CurriculumResult{Course: plan.Course, Version: "2025", Unchanged: 1, Skipped: 2}); result != want {
+		t.Errorf("tercera carga = %+v, se esperaba %+v", result, want)
+	}
+
+	tests := []struct {
+		key         string
+		wantName    string
+		wantCoins   int
+		wantRetired bool
+	}{
+		{"MAT1", "Matemáticas I", 9, false},
+		{"PRG1", "Programación estructurada", 10, false}, // editada por el administrador
+		{"FIS1", "Física I", 8, true},                    // dada de baja por el administrador
+		{"QUI1", "Química", 6, true},                     // ya no está en el plan
+		{"TAL1", "Taller de titulación", 4, false},       // creada por el administrador
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			var name string
+			var coins int
+			var retired bool
+			err := pool.QueryRow(ctx, `
+				SELECT name, coins, retired_at IS NOT NULL FROM academyc_history WHERE key = $1
+			`, tt.key).Scan(&name, &coins, &retired)
+			if err != nil {
+				t.Fatalf("error al leer %s: %v", tt.key, err)
+			}
+			if name != tt.wantName || coins != tt.wantCoins || retired != tt.wantRetired {
+				t.Errorf("%s = (%q, %d, baja %v), se esperaba (%q, %d, baja %v)",
+					tt.key, name, coins, retired, tt.wantName, tt.wantCoins, tt.wantRetired)
+			}
+		})
+	}
+}
+
+// Las materias sin origen que ya coinciden con el plan (p. ej. las de bases
+// anteriores a la migración 0014) pasan a seguir al plan.
+func TestApplyCurriculumAdoptsMatchingSubjects(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		INSERT INTO cat_courses (name) VALUES ('Ingeniería en Computación');
+		INSERT INTO academyc_history (course_id, key, name, coins)
+		SELECT id, 'MAT1', 'Matemáticas I', 8 FROM cat_courses;
+	`)
+
+	plan := curriculum.Curriculum{
+		Version:  "2024",
+		Course:   "Ingeniería en Computación",
+		Subjects: []curriculum.Subject{{Key: "MAT1", Name: "Matemáticas I", Coins: 8}},
+	}
+	result, err := storage.ApplyCurriculum(ctx, plan)
+	if err != nil {
+		t.Fatalf("ApplyCurriculum: %v", err)
+	}
+	if result.Unchanged != 1 || result.Skipped != 0 {
+		t.Fatalf("resultado = %+v, se esperaba MAT1 sin cambios", result)
+	}
+
+	// Ya adoptada, el plan puede darla de baja
+	plan.Version = "2025"
+	plan.Subjects = []curriculum.Subject{{Key: "PRG1", Name: "Programación I", Coins: 10}}
+	result, err = storage.ApplyCurriculum(ctx, plan)
+	if err != nil {
+		t.Fatalf("ApplyCurriculum: %v", err)
+	}
+	if result.Retired != 1 {
+		t.Errorf("resultado = %+v, se esperaba MAT1 dada de baja", result)
+	}
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"alumnos/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// enrollmentConstraint es la restricción única de semester_course por
+// (alumn_id, semester_id, subject_id); ver db/migrations/0013.
+const enrollmentConstraint = "uq_semester_course_enrollment"
+
+// querier lo cumplen el pool y las transacciones.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// DuplicateEnrollmentError indica las materias en las que el alumno ya estaba
+// inscrito en ese semestre; no se inscribió ninguna de la solicitud.
+type DuplicateEnrollmentError struct {
+	SemesterID int
+	Subjects   []models.Subject
+}
+
+func (e *DuplicateEnrollmentError) Error() string {
+	names := make([]string, len(e.Subjects))
+	for i, subject := range e.Subjects {
+		names[i] = fmt.Sprintf("%s (%s)", subject.Name, subject.Key)
+	}
+	return fmt.Sprintf("el alumno ya está inscrito en el semestre %d en: %s", e.SemesterID, strings.Join(names, ", "))
+}
+
+// checkNotEnrolled devuelve un *DuplicateEnrollmentError si el alumno ya
+// cursa alguna de las materias en el semestre.
+func checkNotEnrolled(ctx context.Context, q querier, alumnID, semesterID int, subjectIDs []int) error {
+	rows, err := q.Query(ctx, `
+		SELECT DISTINCT ah.id, ah.key, ah.name, ah.coins
+		FROM semester_course sc
+		JOIN academyc_history ah ON ah.id = sc.subject_id
+		WHERE sc.alumn_id = $1 AND sc.semester_id = $2 AND sc.subject_id = ANY($3)
+		ORDER BY ah.key;
+	`, alumnID, semesterID, subjectIDs)
+	if err != nil {
+		return fmt.Errorf("error al verificar inscripciones existentes: %w", err)
+	}
+	subjects, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.Subject])
+	if err != nil {
+		return fmt.Errorf("error al verificar inscripciones existentes: %w", err)
+	}
+
+	if len(subjects) > 0 {
+		return &DuplicateEnrollmentError{SemesterID: semesterID, Subjects: subjects}
+	}
+	return nil
+}
+
+// enrollmentConflict traduce la violación de la restricción única (dos
+// inscripciones simultáneas que pasaron la verificación) al mismo error que
+// checkNotEnrolled. La transacción ya está abortada: se consulta con el pool.
+func (s *PgxStorage) enrollmentConflict(ctx context.Context, err error, alumnID, semesterID int, subjectIDs []int) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.ConstraintName != enrollmentConstraint {
+		return nil
+	}
+	if checkErr := checkNotEnrolled(ctx, s.DbPool, alumnID, semesterID, subjectIDs); checkErr != nil {
+		return checkErr
+	}
+	return &DuplicateEnrollmentError{SemesterID: semesterID}
+}
+
+// FindDuplicateEnrollments lista las inscripciones repetidas por
+// (alumn_id, semester_id, subject_id) que quedaron de antes de la restricción única.
+func (s *PgxStorage) FindDuplicateEnrollments(ctx context.Context) ([]models.DuplicateEnrollment, error) {
+	ctx = WithOperation(ctx, "FindDuplicateEnrollments")
+	return findDuplicateEnrollments(ctx, s.DbPool)
+}
+
+func findDuplicateEnrollments(ctx context.Context, q querier) ([]models.DuplicateEnrollment, error) {
+	rows, err := q.Query(ctx, `
+		WITH repeated AS (
+			SELECT alumn_id, semester_id, subject_id, array_agg(id ORDER BY id) AS ids
+			FROM semester_course
+			GROUP BY alumn_id, semester_id, subject_id
+			HAVING count(*) > 1
+		)
+		SELECT g.alumn_id, g.semester_id, g.subject_id, ah.key, ah.name, g.ids,
+			(
+				-- Parciales con calificaciones distintas entre las copias
+				SELECT count(*) FROM (
+					SELECT pg.partial_number
+					FROM partial_grades pg
+					WHERE pg.semester_course_id = ANY(g.ids)
+					GROUP BY pg.partial_number
+					HAVING count(DISTINCT pg.grade) > 1
+				) conflicts
+			) AS grade_conflicts,
+			invalid.grades, invalid.partials
+		FROM repeated g
+		JOIN academyc_history ah ON ah.id = g.subject_id
+		-- Parciales que se conservarían (mismo criterio que MergeDuplicateEnrollments)
+		-- y no cumplen chk_partial_grades_grade o chk_partial_grades_partial_number
+		CROSS JOIN LATERAL (
+			SELECT count(*) FILTER (WHERE kept.grade NOT BETWEEN 0 AND 10) AS grades,
+				count(*) FILTER (WHERE kept.partial_number NOT IN (1, 2)) AS partials
+			FROM (
+				SELECT DISTINCT ON (pg.partial_number) pg.partial_number, pg.grade
+				FROM partial_grades pg
+				WHERE pg.semester_course_id = ANY(g.ids)
+				ORDER BY pg.partial_number, pg.updated_at DESC NULLS LAST, pg.id DESC
+			) kept
+		) invalid
+		ORDER BY g.alumn_id, g.semester_id, ah.key;
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar inscripciones repetidas: %w", err)
+	}
+
+	duplicates, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.DuplicateEnrollment, error) {
+		var d models.DuplicateEnrollment
+		var ids []int
+		err := row.Scan(&d.AlumnID, &d.SemesterID, &d.SubjectID, &d.SubjectKey, &d.SubjectName, &ids,
+			&d.GradeConflicts, &d.InvalidGrades, &d.InvalidPartials)
+		if len(ids) > 0 {
+			d.KeptID, d.MergedIDs = ids[0], ids[1:]
+		}
+		return d, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar inscripciones repetidas: %w", err)
+	}
+	return duplicates, nil
+}
+
+// MergeDuplicateEnrollments une las inscripciones repetidas en la más antigua
+// de cada grupo, en una sola transacción:
+//   - de cada parcial se conserva la calificación modificada más recientemente;
+//   - los grupos cuyos parciales conservados no cumplen las restricciones de la
+//     migración 0010 (DuplicateEnrollment.NeedsManualFix) no se tocan: unirlos
+//     obligaría a cambiar o descartar calificaciones reales, así que se
+//     devuelven en skipped para corregirlos a mano;
+//   - el periodo se completa desde las copias si falta y la calificación final
+//     se recalcula con los parciales que quedan, como update_final_grade;
+//   - las copias se borran y se recalculan los promedios de semestre
+//     afectados, que contaban las copias dos veces.
+//
+// Devuelve los grupos que unió y los que omitió.
+func (s *PgxStorage) MergeDuplicateEnrollments(ctx context.Context) (merged, skipped []models.DuplicateEnrollment, err error) {
+	ctx = WithOperation(ctx, "MergeDuplicateEnrollments")
+	tx, err := s.DbPool.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Evita que se inscriba a alguien mientras se une
+	if _, err := tx.Exec(ctx, `LOCK TABLE semester_course IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, nil, fmt.Errorf("error al bloquear semester_course: %w", err)
+	}
+
+	duplicates, err := findDuplicateEnrollments(ctx, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var keepIDs []int
+	for _, d := range duplicates {
+		if d.NeedsManualFix() {
+			skipped = append(skipped, d)
+			continue
+		}
+		merged = append(merged, d)
+		keepIDs = append(keepIDs, d.KeptID)
+	}
+	if len(merged) == 0 {
+		return nil, skipped, nil
+	}
+
+	steps := []struct {
+		name  string
+		query string
+		args  []any
+	}{
+		{"agrupar inscripciones", `
+			CREATE TEMP TABLE enrollment_remap ON COMMIT DROP AS
+			SELECT id AS old_id, keep_id, alumn_id, semester_id
+			FROM (
+				SELECT id, alumn_id, semester_id,
+					MIN(id) OVER (PARTITION BY alumn_id, semester_id, subject_id) AS keep_id
+				FROM semester_course
+			) ranked
+			WHERE id <> keep_id AND keep_id = ANY($1);
+		`, []any{keepIDs}},
+		{"elegir parciales", `
+			CREATE TEMP TABLE merged_partials ON COMMIT DROP AS
+			SELECT DISTINCT ON (keep_id, pg.partial_number)
+				keep_id, pg.partial_number, pg.grade, pg.created_at, pg.updated_at
+			FROM partial_grades pg
+			JOIN (
+				SELECT old_id AS id, keep_id FROM enrollment_remap
+				UNION
+				SELECT keep_id, keep_id FROM enrollment_remap
+			) copies ON copies.id = pg.semester_course_id
+			ORDER BY keep_id, pg.partial_number, pg.updated_at DESC NULLS LAST, pg.id DESC;
+		`, nil},
+		{"completar la inscripción conservada", `
+			UPDATE semester_course k
+			SET period_id = COALESCE(k.period_id, d.period_id),
+				updated_at = CURRENT_TIMESTAMP
+			FROM (
+				SELECT DISTINCT ON (r.keep_id) r.keep_id, sc.period_id
+				FROM enrollment_remap r
+				JOIN semester_course sc ON sc.id = r.old_id
+				ORDER BY r.keep_id, sc.updated_at DESC NULLS LAST, sc.id DESC
+			) d
+			WHERE k.id = d.keep_id;
+		`, nil},
+		// Los parciales de las copias se borran en cascada
+		{"borrar copias", `
+			DELETE FROM semester_course sc
+			USING enrollment_remap r
+			WHERE sc.id = r.old_id;
+		`, nil},
+		{"borrar parciales conservados", `
+			DELETE FROM partial_grades
+			WHERE semester_course_id IN (SELECT keep_id FROM enrollment_remap);
+		`, nil},
+		// Los grupos con parciales inválidos se omitieron, así que se insertan sin cambios
+		{"insertar parciales unidos", `
+			INSERT INTO partial_grades (semester_course_id, partial_number, grade, created_at, updated_at)
+			SELECT keep_id, partial_number, grade, created_at, updated_at
+			FROM merged_partials;
+		`, nil},
+		// Misma regla que update_final_grade, que solo actúa al llegar al
+		// segundo parcial: sin ambos parciales no hay calificación final
+		{"recalcular calificaciones finales", `
+			UPDATE semester_course sc
+			SET final_grade = (
+					SELECT CASE WHEN count(*) = 2 THEN AVG(pg.grade) END
+					FROM partial_grades pg
+					WHERE pg.semester_course_id = sc.id
+				),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE sc.id IN (SELECT keep_id FROM enrollment_remap);
+		`, nil},
+		{"recalcular promedios de semestre", `
+			UPDATE semester_grades sg
+			SET final_semester_grade = recalculated.grade, updated_at = CURRENT_TIMESTAMP
+			FROM (
+				SELECT sc.alumn_id, sc.semester_id,
+					CASE WHEN count(*) = count(sc.final_grade) THEN AVG(sc.final_grade) END AS grade
+				FROM semester_course sc
+				JOIN (SELECT DISTINCT alumn_id, semester_id FROM enrollment_remap) affected
+					ON affected.alumn_id = sc.alumn_id AND affected.semester_id = sc.semester_id
+				GROUP BY sc.alumn_id, sc.semester_id
+			) recalculated
+			WHERE sg.alumn_id = recalculated.alumn_id AND sg.semester_id = recalculated.semester_id;
+		`, nil},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(ctx, step.query, step.args...); err != nil {
+			return nil, nil, fmt.Errorf("error al unir inscripciones (%s): %w", step.name, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+	return merged, skipped, nil
+}
@@ -0,0 +1,163 @@
+package repository_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// partialGrades devuelve las calificaciones de una inscripción por número de parcial.
+func partialGrades(t *testing.T, pool *pgxpool.Pool, enrollmentID int) []float64 {
+	t.Helper()
+	rows, err := pool.Query(context.Background(),
+		`SELECT grade FROM partial_grades WHERE semester_course_id = $1 ORDER BY partial_number`, enrollmentID)
+	if err != nil {
+		t.Fatalf("error al leer parciales: %v", err)
+	}
+	grades, err := pgx.CollectRows(rows, pgx.RowTo[float64])
+	if err != nil {
+		t.Fatalf("error al leer parciales: %v", err)
+	}
+	return grades
+}
+
+// Al unir inscripciones repetidas no se cambia ninguna calificación: los grupos
+// con parciales que no cumplen las restricciones NOT VALID de la migración
+// 0010 se omiten hasta corregirlos a mano, y la calificación final se
+// recalcula con los parciales que quedan.
+func TestMergeDuplicateEnrollments(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		-- Datos anteriores a las migraciones 0010 y 0013
+		ALTER TABLE semester_course DROP CONSTRAINT uq_semester_course_enrollment;
+		ALTER TABLE partial_grades DROP CONSTRAINT chk_partial_grades_grade;
+		ALTER TABLE partial_grades DROP CONSTRAINT chk_partial_grades_partial_number;
+
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO cat_semesters (id, name) VALUES (1, 'Primer semestre');
+		INSERT INTO academyc_history (id, course_id, key, name) VALUES (1, 1, 'MAT1', 'Matemáticas'), (2, 1, 'PRG1', 'Programación');
+		INSERT INTO alumn (id, name, lastname1, course_id, current_semester) VALUES
+			(1, 'Ana', 'García', 1, 1),
+			(2, 'Luis', 'Pérez', 1, 1);
+		INSERT INTO semester_course (id, alumn_id, semester_id, subject_id) VALUES
+			(1, 1, 1, 1), (2, 1, 1, 1),  -- se unen: ambos parciales
+			(3, 1, 1, 2), (4, 1, 1, 2),  -- se unen: un solo parcial
+			(5, 2, 1, 1), (6, 2, 1, 1),  -- se omiten: calificación fuera de rango
+			(7, 2, 1, 2), (8, 2, 1, 2);  -- se omiten: parcial inexistente
+		INSERT INTO partial_grades (semester_course_id, partial_number, grade, updated_at) VALUES
+			(1, 1, 12, CURRENT_TIMESTAMP - interval '2 days'), -- inválida, pero la reemplaza la más reciente
+			(2, 1, 7, CURRENT_TIMESTAMP - interval '1 day'),
+			(2, 2, 9, CURRENT_TIMESTAMP),
+			(3, 1, 8, CURRENT_TIMESTAMP),
+			(5, 1, 6, CURRENT_TIMESTAMP - interval '1 day'),
+			(6, 1, 11, CURRENT_TIMESTAMP),
+			(7, 1, 8, CURRENT_TIMESTAMP),
+			(8, 3, 7, CURRENT_TIMESTAMP);
+		-- Calificaciones finales que ya no corresponden a los parciales unidos
+		UPDATE semester_course SET final_grade = 5 WHERE id = 1;
+		UPDATE semester_course SET final_grade = 9 WHERE id = 4;
+
+		ALTER TABLE partial_grades ADD CONSTRAINT chk_partial_grades_grade CHECK (grade BETWEEN 0 AND 10) NOT VALID;
+		ALTER TABLE partial_grades ADD CONSTRAINT chk_partial_grades_partial_number CHECK (partial_number IN (1, 2)) NOT VALID;
+	`)
+
+	found, err := storage.FindDuplicateEnrollments(ctx)
+	if err != nil {
+		t.Fatalf("FindDuplicateEnrollments: %v", err)
+	}
+	if len(found) != 4 {
+		t.Fatalf("grupos encontrados = %d, se esperaba 4", len(found))
+	}
+	for i, want := range []struct {
+		keptID, conflicts, invalidGrades, invalidPartials int
+		manual                                            bool
+	}{
+		{1, 1, 0, 0, false},
+		{3, 0, 0, 0, false},
+		{5, 1, 1, 0, true},
+		{7, 0, 0, 1, true},
+	} {
+		d := found[i]
+		if d.KeptID != want.keptID || d.GradeConflicts != want.conflicts ||
+			d.InvalidGrades != want.invalidGrades || d.InvalidPartials != want.invalidPartials ||
+			d.NeedsManualFix() != want.manual {
+			t.Errorf("grupo %d = %+v, se esperaba %+v", i, d, want)
+		}
+	}
+
+	merged, skipped, err := storage.MergeDuplicateEnrollments(ctx)
+	if err != nil {
+		t.Fatalf("MergeDuplicateEnrollments: %v", err)
+	}
+	var mergedIDs, skippedIDs []int
+	for _, d := range merged {
+		mergedIDs = append(mergedIDs, d.KeptID)
+	}
+	for _, d := range skipped {
+		skippedIDs = append(skippedIDs, d.KeptID)
+	}
+	if !slices.Equal(mergedIDs, []int{1, 3}) || !slices.Equal(skippedIDs, []int{5, 7}) {
+		t.Fatalf("unidos = %v, omitidos = %v; se esperaba [1 3] y [5 7]", mergedIDs, skippedIDs)
+	}
+
+	var remaining []int
+	rows, err := pool.Query(ctx, `SELECT id FROM semester_course ORDER BY id`)
+	if err != nil {
+		t.Fatalf("error al leer inscripciones: %v", err)
+	}
+	if remaining, err = pgx.CollectRows(rows, pgx.RowTo[int]); err != nil {
+		t.Fatalf("error al leer inscripciones: %v", err)
+	}
+	if !slices.Equal(remaining, []int{1, 3, 5, 6, 7, 8}) {
+		t.Errorf("inscripciones = %v, se esperaba [1 3 5 6 7 8]", remaining)
+	}
+
+	tests := []struct {
+		name         string
+		enrollmentID int
+		wantPartials []float64
+		wantFinal    *float64
+	}{
+		{"unida con ambos parciales", 1, []float64{7, 9}, ptr(8.0)},
+		{"unida con un parcial no tiene final", 3, []float64{8}, nil},
+		{"omitida sin cambios", 6, []float64{11}, nil},
+		{"parcial inexistente sin cambios", 8, []float64{7}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := partialGrades(t, pool, tt.enrollmentID); !slices.Equal(got, tt.wantPartials) {
+				t.Errorf("parciales = %v, se esperaba %v", got, tt.wantPartials)
+			}
+			var final *float64
+			if err := pool.QueryRow(ctx, `SELECT final_grade FROM semester_course WHERE id = $1`, tt.enrollmentID).Scan(&final); err != nil {
+				t.Fatalf("error al leer la calificación final: %v", err)
+			}
+			if (final == nil) != (tt.wantFinal == nil) || (final != nil && *final != *tt.wantFinal) {
+				t.Errorf("calificación final = %v, se esperaba %v", final, tt.wantFinal)
+			}
+		})
+	}
+
+	// Corregidos a mano, los grupos omitidos se unen en la siguiente ejecución
+	exec(t, pool, `
+		UPDATE partial_grades SET grade = 10 WHERE semester_course_id = 6;
+		UPDATE partial_grades SET partial_number = 2 WHERE semester_course_id = 8;
+	`)
+	merged, skipped, err = storage.MergeDuplicateEnrollments(ctx)
+	if err != nil {
+		t.Fatalf("MergeDuplicateEnrollments tras corregir: %v", err)
+	}
+	if len(merged) != 2 || len(skipped) != 0 {
+		t.Fatalf("unidos = %d, omitidos = %d; se esperaba 2 y 0", len(merged), len(skipped))
+	}
+	if got := partialGrades(t, pool, 7); !slices.Equal(got, []float64{8, 7}) {
+		t.Errorf("parciales = %v, se esperaba [8 7]", got)
+	}
+
+	// Ya sin copias, la restricción única se puede agregar
+	exec(t, pool, `ALTER TABLE semester_course ADD CONSTRAINT uq_semester_course_enrollment UNIQUE (alumn_id, semester_id, subject_id)`)
+}
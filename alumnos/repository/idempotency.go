@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"alumnos/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ClaimIdempotencyKey reserva key para la solicitud con requestHash. Si la
+// clave es nueva (o la anterior venció, o quedó en curso más de abandonAfter
+// porque el servidor se detuvo) devuelve nil y la solicitud debe ejecutarse.
+// Si no, devuelve lo guardado para que el handler responda con eso.
+func (s *PgxStorage) ClaimIdempotencyKey(ctx context.Context, scope, key, requestHash string, ttl, abandonAfter time.Duration) (*models.StoredResponse, error) {
+	ctx = WithOperation(ctx, "ClaimIdempotencyKey")
+	var claimed bool
+	err := s.DbPool.QueryRow(ctx, `
+		INSERT INTO idempotency_keys (scope, key, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = NULL,
+		    response_body = NULL, created_at = CURRENT_TIMESTAMP, completed_at = NULL
+		WHERE idempotency_keys.created_at < CURRENT_TIMESTAMP - $4::interval
+		   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < CURRENT_TIMESTAMP - $5::interval)
+		RETURNING true;
+	`, scope, key, requestHash, ttl, abandonAfter).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("error al reservar la clave de idempotencia: %w", err)
+	}
+
+	// La clave ya existe y sigue vigente
+	var stored models.StoredResponse
+	var status *int
+	var contentType *string
+	err = s.DbPool.QueryRow(ctx, `
+		SELECT request_hash, status_code, content_type, response_body
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2;
+	`, scope, key).Scan(&stored.RequestHash, &status, &contentType, &stored.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar la clave de idempotencia: %w", err)
+	}
+	if status != nil {
+		stored.StatusCode = *status
+	}
+	if contentType != nil {
+		stored.ContentType = *contentType
+	}
+	return &stored, nil
+}
+
+// SaveIdempotentResponse guarda la respuesta de una clave reservada.
+func (s *PgxStorage) SaveIdempotentResponse(ctx context.Context, scope, key string, response models.StoredResponse) error {
+	ctx = WithOperation(ctx, "SaveIdempotentResponse")
+	_, err := s.DbPool.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, response_body = $5, completed_at = CURRENT_TIMESTAMP
+		WHERE scope = $1 AND key = $2;
+	`, scope, key, response.StatusCode, response.ContentType, response.Body)
+	if err != nil {
+		return fmt.Errorf("error al guardar la respuesta idempotente: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey borra una clave reservada cuya solicitud falló por un
+// error del servidor, para que el reintento vuelva a ejecutarse.
+func (s *PgxStorage) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	ctx = WithOperation(ctx, "ReleaseIdempotencyKey")
+	_, err := s.DbPool.Exec(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status_code IS NULL`, scope, key)
+	if err != nil {
+		return fmt.Errorf("error al liberar la clave de idempotencia: %w", err)
+	}
+	return nil
+}
+
+// PurgeIdempotencyKeys borra las claves vencidas y devuelve cuántas borró.
+func (s *PgxStorage) PurgeIdempotencyKeys(ctx context.Context, ttl time.Duration) (int64, error) {
+	ctx = WithOperation(ctx, "PurgeIdempotencyKeys")
+	tag, err := s.DbPool.Exec(ctx, `DELETE FROM idempotency_keys WHERE created_at < CURRENT_TIMESTAMP - $1::interval`, ttl)
+	if err != nil {
+		return 0, fmt.Errorf("error al borrar claves de idempotencia vencidas: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,97 @@
+package repository_test
+
+import (
+	"alumnos/models"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := context.Background()
+
+	done := models.StoredResponse{RequestHash: "h1", StatusCode: 201, ContentType: "application/json", Body: []byte(`{"id":1}`)}
+
+	tests := []struct {
+		name         string
+		key          string
+		save         bool          // guardar la respuesta tras la primera reserva
+		ttl, abandon time.Duration // para la segunda reserva
+		wantClaimed  bool
+		wantStatus   int
+	}{
+		{"terminada y vigente", "a", true, time.Hour, time.Hour, false, 201},
+		{"en curso", "b", false, time.Hour, time.Hour, false, 0},
+		{"terminada y vencida", "c", true, 0, time.Hour, true, 0},
+		{"en curso y abandonada", "d", false, time.Hour, 0, true, 0},
+		{"abandono no aplica a las terminadas", "e", true, time.Hour, 0, false, 201},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := storage.ClaimIdempotencyKey(ctx, "POST /v1/alumnos", tt.key, "h1", time.Hour, time.Hour)
+			if err != nil || stored != nil {
+				t.Fatalf("primera reserva = (%v, %v), se esperaba (nil, nil)", stored, err)
+			}
+			if tt.save {
+				if err := storage.SaveIdempotentResponse(ctx, "POST /v1/alumnos", tt.key, done); err != nil {
+					t.Fatalf("SaveIdempotentResponse: %v", err)
+				}
+			}
+
+			stored, err = storage.ClaimIdempotencyKey(ctx, "POST /v1/alumnos", tt.key, "h2", tt.ttl, tt.abandon)
+			if err != nil {
+				t.Fatalf("ClaimIdempotencyKey: %v", err)
+			}
+			if claimed := stored == nil; claimed != tt.wantClaimed {
+				t.Fatalf("reservada = %v, se esperaba %v", claimed, tt.wantClaimed)
+			}
+			if tt.wantClaimed {
+				return
+			}
+			if stored.RequestHash != "h1" || stored.StatusCode != tt.wantStatus {
+				t.Errorf("guardada = %+v, se esperaba hash h1 y status %d", stored, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 && string(stored.Body) != string(done.Body) {
+				t.Errorf("cuerpo = %q, se esperaba %q", stored.Body, done.Body)
+			}
+		})
+	}
+}
+
+func TestReleaseAndPurgeIdempotencyKeys(t *testing.T) {
+	storage, _ := newStorage(t)
+	ctx := context.Background()
+	scope := "POST /v1/semestres"
+
+	for _, key := range []string{"en-curso", "terminada"} {
+		if _, err := storage.ClaimIdempotencyKey(ctx, scope, key, "h", time.Hour, time.Hour); err != nil {
+			t.Fatalf("ClaimIdempotencyKey: %v", err)
+		}
+	}
+	if err := storage.SaveIdempotentResponse(ctx, scope, "terminada", models.StoredResponse{StatusCode: 201}); err != nil {
+		t.Fatalf("SaveIdempotentResponse: %v", err)
+	}
+
+	// Liberar solo borra las claves en curso
+	for _, key := range []string{"en-curso", "terminada"} {
+		if err := storage.ReleaseIdempotencyKey(ctx, scope, key); err != nil {
+			t.Fatalf("ReleaseIdempotencyKey: %v", err)
+		}
+	}
+	if stored, _ := storage.ClaimIdempotencyKey(ctx, scope, "en-curso", "h", time.Hour, time.Hour); stored != nil {
+		t.Error("la clave en curso no se liberó")
+	}
+	if stored, _ := storage.ClaimIdempotencyKey(ctx, scope, "terminada", "h", time.Hour, time.Hour); stored == nil {
+		t.Error("se liberó una clave terminada")
+	}
+
+	purged, err := storage.PurgeIdempotencyKeys(ctx, 0)
+	if err != nil {
+		t.Fatalf("PurgeIdempotencyKeys: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("PurgeIdempotencyKeys borró %d claves, se esperaban 2", purged)
+	}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import "context"
+
+type operationKey struct{}
+
+// WithOperation nombra las consultas que se ejecuten con el contexto
+// devuelto. Cada método de PgxStorage pone su nombre, p. ej. "GetStudents";
+// el código que consulta la base de datos fuera del repositorio (migraciones,
+// health checks) pone el suyo. Los tracers de pgx lo usan para atribuir cada
+// consulta en métricas y trazas.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// Operation devuelve el nombre puesto con WithOperation, o "other".
+func Operation(ctx context.Context) string {
+	if name, ok := ctx.Value(operationKey{}).(string); ok {
+		return name
+	}
+	return "other"
+}
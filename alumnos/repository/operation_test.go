@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestOperation(t *testing.T) {
+	ctx := context.Background()
+	if got := Operation(ctx); got != "other" {
+		t.Errorf("Operation() sin nombre = %q, se esperaba %q", got, "other")
+	}
+	ctx = WithOperation(ctx, "GetStudents")
+	if got := Operation(WithOperation(ctx, "activeAlumn")); got != "activeAlumn" {
+		t.Errorf("Operation() anidada = %q, se esperaba %q", got, "activeAlumn")
+	}
+	if got := Operation(ctx); got != "GetStudents" {
+		t.Errorf("Operation() = %q, se esperaba %q", got, "GetStudents")
+	}
+}
+
+// Cada método exportado de PgxStorage debe empezar con
+// ctx = WithOperation(ctx, "<nombre del método>") para que sus consultas se
+// atribuyan a él en métricas y trazas.
+func TestMethodsSetOperation(t *testing.T) {
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	methods := 0
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatalf("error al leer %s: %v", path, err)
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || !fn.Name.IsExported() || !takesContext(fn) {
+				continue
+			}
+			if star, ok := fn.Recv.List[0].Type.(*ast.StarExpr); !ok || star.X.(*ast.Ident).Name != "PgxStorage" {
+				continue
+			}
+			methods++
+			if got := operationName(fn); got != fn.Name.Name {
+				t.Errorf("%s: %s empieza con WithOperation(ctx, %q)", fset.Position(fn.Pos()), fn.Name.Name, got)
+			}
+		}
+	}
+	if methods == 0 {
+		t.Fatal("no se encontraron métodos de PgxStorage")
+	}
+}
+
+func takesContext(fn *ast.FuncDecl) bool {
+	params := fn.Type.Params.List
+	if len(params) == 0 {
+		return false
+	}
+	sel, ok := params[0].Type.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Context"
+}
+
+// operationName devuelve el nombre de ctx = WithOperation(ctx, "...") si es la
+// primera sentencia de fn.
+func operationName(fn *ast.FuncDecl) string {
+	if len(fn.Body.List) == 0 {
+		return ""
+	}
+	assign, ok := fn.Body.List[0].(*ast.AssignStmt)
+	if !ok || len(assign.Rhs) != 1 {
+		return ""
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return ""
+	}
+	if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != "WithOperation" {
+		return ""
+	}
+	lit, ok := call.Args[1].(*ast.BasicLit)
+	if !ok {
+		return ""
+	}
+	name, _ := strconv.Unquote(lit.Value)
+	return name
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"alumnos/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrDuplicatePeriodCode = errors.New("ya existe un periodo con ese código")
+	ErrPeriodOverlap       = errors.New("el periodo se traslapa con otro existente")
+)
+
+func (s *PgxStorage) GetAcademicPeriods(ctx context.Context) ([]models.AcademicPeriod, error) {
+	ctx = WithOperation(ctx, "GetAcademicPeriods")
+	query := `
+		SELECT id, code, start_date, end_date, created_at, updated_at
+		FROM academic_periods
+		ORDER BY start_date DESC;
+	`
+
+	rows, err := s.DbPool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener periodos: %w", err)
+	}
+	defer rows.Close()
+
+	periods := []models.AcademicPeriod{}
+	for rows.Next() {
+		var p models.AcademicPeriod
+		if err := rows.Scan(&p.ID, &p.Code, &p.StartDate, &p.EndDate, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error al escanear periodos: %w", err)
+		}
+		periods = append(periods, p)
+	}
+
+	return periods, nil
+}
+
+func (s *PgxStorage) CreateAcademicPeriod(ctx context.Context, code string, start, end time.Time) (*models.AcademicPeriod, error) {
+	ctx = WithOperation(ctx, "CreateAcademicPeriod")
+	var codeExists, overlaps bool
+	err := s.DbPool.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM academic_periods WHERE code = $1),
+			EXISTS (SELECT 1 FROM academic_periods WHERE start_date <= $3 AND end_date >= $2);
+	`, code, start, end).Scan(&codeExists, &overlaps)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar periodo: %w", err)
+	}
+	if codeExists {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicatePeriodCode, code)
+	}
+	if overlaps {
+		return nil, ErrPeriodOverlap
+	}
+
+	query := `
+		INSERT INTO academic_periods (code, start_date, end_date)
+		VALUES ($1, $2, $3)
+		RETURNING id, code, start_date, end_date, created_at, updated_at;
+	`
+
+	var p models.AcademicPeriod
+	err = s.DbPool.QueryRow(ctx, query, code, start, end).
+		Scan(&p.ID, &p.Code, &p.StartDate, &p.EndDate, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear periodo: %w", err)
+	}
+
+	return &p, nil
+}
+
+// resolvePeriodID devuelve el periodo indicado por código o, si code está
+// vacío, el periodo vigente en la fecha actual. Sin periodo vigente devuelve nil.
+func resolvePeriodID(ctx context.Context, tx pgx.Tx, code string) (*int, error) {
+	var id int
+	if code != "" {
+		err := tx.QueryRow(ctx, `SELECT id FROM academic_periods WHERE code = $1`, code).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("periodo %s: %w", code, err)
+		}
+		return &id, nil
+	}
+
+	err := tx.QueryRow(ctx, `
+		SELECT id FROM academic_periods
+		WHERE CURRENT_DATE BETWEEN start_date AND end_date
+		ORDER BY start_date DESC
+		LIMIT 1;
+	`).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener periodo vigente: %w", err)
+	}
+
+	return &id, nil
+}
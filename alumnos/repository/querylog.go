@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"alumnos/logging"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QueryLogger implementa pgx.QueryTracer: registra cada consulta en nivel
+// debug y las fallidas en warn, con el request_id de la solicitud que la
+// originó. Los argumentos nunca se registran porque contienen datos personales.
+type QueryLogger struct {
+	Logger *slog.Logger
+}
+
+var _ pgx.QueryTracer = (*QueryLogger)(nil)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql   string
+	start time.Time
+}
+
+func (q *QueryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, start: time.Now()})
+}
+
+func (q *QueryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	started, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	logger := logging.FromContext(ctx, q.Logger)
+	attrs := []slog.Attr{
+		slog.String("sql", compactSQL(started.sql)),
+		slog.Float64("duration_ms", float64(time.Since(started.start).Microseconds())/1000),
+	}
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		logger.LogAttrs(ctx, slog.LevelWarn, "consulta fallida", append(attrs, slog.Any("error", data.Err))...)
+		return
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, "consulta", append(attrs, slog.Int64("rows", data.CommandTag.RowsAffected()))...)
+}
+
+// compactSQL junta la consulta en una línea para que quepa en un registro.
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"alumnos/models"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// subjectKeyConstraint es la restricción única de academyc_history por
+// (course_id, key); ver db/migrations/0008_dedupe_catalog.sql.
+const subjectKeyConstraint = "uq_academyc_history_course_key"
+
+var (
+	ErrDuplicateSubjectKey = errors.New("ya existe una materia con esa clave en el curso")
+	ErrSubjectInUse        = errors.New("la materia ya está asignada a alumnos")
+)
+
+func (s *PgxStorage) CreateSubject(ctx context.Context, courseID int, request models.SubjectRequest) (*models.Subject, error) {
+	ctx = WithOperation(ctx, "CreateSubject")
+	var courseExists bool
+	err := s.DbPool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM cat_courses WHERE id = $1)`, courseID).Scan(&courseExists)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar curso %d: %w", courseID, err)
+	}
+	if !courseExists {
+		return nil, fmt.Errorf("curso %d: %w", courseID, pgx.ErrNoRows)
+	}
+
+	if err := s.checkSubjectKey(ctx, courseID, *request.Key, 0); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO academyc_history (course_id, key, name, coins)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, key, name, coins;
+	`
+
+	var subject models.Subject
+	err = s.DbPool.QueryRow(ctx, query, courseID, *request.Key, *request.Name, *request.Coins).
+		Scan(&subject.ID, &subject.Key, &subject.Name, &subject.Coins)
+	if err != nil {
+		if conflict := subjectKeyConflict(err, request.Key); conflict != nil {
+			return nil, conflict
+		}
+		return nil, fmt.Errorf("error al crear materia: %w", err)
+	}
+
+	return &subject, nil
+}
+
+// UpdateSubject aplica solo los campos enviados en la solicitud. La materia
+// deja de seguir al plan de estudios para que ApplyCurriculum no revierta el cambio.
+func (s *PgxStorage) UpdateSubject(ctx context.Context, subjectID int, request models.SubjectRequest) (*models.Subject, error) {
+	ctx = WithOperation(ctx, "UpdateSubject")
+	var courseID int
+	err := s.DbPool.QueryRow(ctx, `SELECT course_id FROM academyc_history WHERE id = $1`, subjectID).Scan(&courseID)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener materia %d: %w", subjectID, err)
+	}
+
+	if request.Key != nil {
+		if err := s.checkSubjectKey(ctx, courseID, *request.Key, subjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		UPDATE academyc_history
+		SET key = COALESCE($2, key),
+			name = COALESCE($3, name),
+			coins = COALESCE($4, coins),
+			curriculum_version = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING id, key, name, coins;
+	`
+
+	var subject models.Subject
+	err = s.DbPool.QueryRow(ctx, query, subjectID, request.Key, request.Name, request.Coins).
+		Scan(&subject.ID, &subject.Key, &subject.Name, &subject.Coins)
+	if err != nil {
+		if conflict := subjectKeyConflict(err, request.Key); conflict != nil {
+			return nil, conflict
+		}
+		return nil, fmt.Errorf("error al actualizar materia %d: %w", subjectID, err)
+	}
+
+	return &subject, nil
+}
+
+// RetireSubject da de baja la materia del plan de estudios sin borrar el
+// historial. Como en UpdateSubject, la materia deja de seguir al plan.
+func (s *PgxStorage) RetireSubject(ctx context.Context, subjectID int) error {
+	ctx = WithOperation(ctx, "RetireSubject")
+	query := `
+		UPDATE academyc_history
+		SET retired_at = CURRENT_TIMESTAMP, curriculum_version = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND retired_at IS NULL;
+	`
+
+	tag, err := s.DbPool.Exec(ctx, query, subjectID)
+	if err != nil {
+		return fmt.Errorf("error al dar de baja materia %d: %w", subjectID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error al dar de baja materia %d: %w", subjectID, pgx.ErrNoRows)
+	}
+
+	return nil
+}
+
+// DeleteSubject borra la materia solo si ningún alumno la ha cursado; en otro
+// caso debe usarse RetireSubject para no perder calificaciones en cascada.
+func (s *PgxStorage) DeleteSubject(ctx context.Context, subjectID int) error {
+	ctx = WithOperation(ctx, "DeleteSubject")
+	tx, err := s.DbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Bloquear la materia para que no se inscriba nadie mientras se borra
+	var id int
+	err = tx.QueryRow(ctx, `SELECT id FROM academyc_history WHERE id = $1 FOR UPDATE`, subjectID).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("error al obtener materia %d: %w", subjectID, err)
+	}
+
+	var inUse bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM semester_course WHERE subject_id = $1)`, subjectID).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("error al verificar uso de materia %d: %w", subjectID, err)
+	}
+	if inUse {
+		return ErrSubjectInUse
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM academyc_history WHERE id = $1`, subjectID); err != nil {
+		return fmt.Errorf("error al eliminar materia %d: %w", subjectID, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error al confirmar transacción: %w", err)
+	}
+
+	return nil
+}
+
+// checkSubjectKey valida que la clave no exista en el curso, ignorando la propia materia al actualizar.
+func (s *PgxStorage) checkSubjectKey(ctx context.Context, courseID int, key string, subjectID int) error {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM academyc_history
+			WHERE course_id = $1 AND key = $2 AND id <> $3
+		);
+	`
+
+	var exists bool
+	if err := s.DbPool.QueryRow(ctx, query, courseID, key, subjectID).Scan(&exists); err != nil {
+		return fmt.Errorf("error al verificar clave de materia: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateSubjectKey, key)
+	}
+
+	return nil
+}
+
+// subjectKeyConflict traduce la violación de la restricción única (dos
+// solicitudes simultáneas que pasaron checkSubjectKey) a ErrDuplicateSubjectKey.
+func subjectKeyConflict(err error, key *string) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.ConstraintName != subjectKeyConstraint || key == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDuplicateSubjectKey, *key)
+}
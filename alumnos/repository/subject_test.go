@@ -0,0 +1,171 @@
+package repository_test
+
+import (
+	"alumnos/models"
+	"alumnos/repository"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestSubjectDuplicateKey(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería'), (2, 'Física');
+		INSERT INTO academyc_history (id, course_id, key, name, coins) VALUES
+			(1, 1, 'MAT1', 'Matemáticas I', 8),
+			(2, 1, 'PRG1', 'Programación I', 10);
+	`)
+
+	request := func(key string) models.SubjectRequest {
+		return models.SubjectRequest{Key: ptr(key), Name: ptr("Materia"), Coins: ptr(6)}
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{"crear con clave repetida", func() error {
+			_, err := storage.CreateSubject(ctx, 1, request("MAT1"))
+			return err
+		}, repository.ErrDuplicateSubjectKey},
+		{"la misma clave en otra carrera", func() error {
+			_, err := storage.CreateSubject(ctx, 2, request("MAT1"))
+			return err
+		}, nil},
+		{"crear en una carrera inexistente", func() error {
+			_, err := storage.CreateSubject(ctx, 99, request("QUI1"))
+			return err
+		}, pgx.ErrNoRows},
+		{"cambiar a una clave ocupada", func() error {
+			_, err := storage.UpdateSubject(ctx, 2, models.SubjectRequest{Key: ptr("MAT1")})
+			return err
+		}, repository.ErrDuplicateSubjectKey},
+		{"conservar la propia clave", func() error {
+			_, err := storage.UpdateSubject(ctx, 1, models.SubjectRequest{Key: ptr("MAT1"), Coins: ptr(9)})
+			return err
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, se esperaba %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Dos altas simultáneas con la misma clave pueden pasar checkSubjectKey; la
+// restricción única detiene a una y se informa como clave repetida.
+func TestCreateSubjectConcurrent(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería')`)
+
+	request := models.SubjectRequest{Key: ptr("MAT1"), Name: ptr("Matemáticas I"), Coins: ptr(8)}
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = storage.CreateSubject(ctx, 1, request)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, repository.ErrDuplicateSubjectKey):
+			t.Errorf("error = %v, se esperaba ErrDuplicateSubjectKey", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("altas exitosas = %d, se esperaba 1", succeeded)
+	}
+}
+
+func TestDeleteSubject(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO cat_semesters (id, name) VALUES (1, 'Primer semestre');
+		INSERT INTO academyc_history (id, course_id, key, name, coins) VALUES
+			(1, 1, 'MAT1', 'Matemáticas I', 8),
+			(2, 1, 'PRG1', 'Programación I', 10);
+		INSERT INTO alumn (id, name, lastname1, course_id, current_semester) VALUES (1, 'Ana', 'García', 1, 1);
+		INSERT INTO semester_course (alumn_id, semester_id, subject_id) VALUES (1, 1, 1);
+	`)
+
+	if err := storage.DeleteSubject(ctx, 1); !errors.Is(err, repository.ErrSubjectInUse) {
+		t.Errorf("materia cursada: error = %v, se esperaba ErrSubjectInUse", err)
+	}
+	if err := storage.DeleteSubject(ctx, 2); err != nil {
+		t.Errorf("materia sin alumnos: %v", err)
+	}
+	if err := storage.DeleteSubject(ctx, 2); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("materia ya borrada: error = %v, se esperaba pgx.ErrNoRows", err)
+	}
+
+	var count int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM semester_course WHERE subject_id = 1`).Scan(&count); err != nil {
+		t.Fatalf("error al contar inscripciones: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("inscripciones de la materia cursada = %d, se esperaba 1", count)
+	}
+}
+
+// Una inscripción sin confirmar bloquea la materia (FOR KEY SHARE de la llave
+// foránea), así que DeleteSubject espera en el FOR UPDATE y, al confirmarse la
+// inscripción, la ve y se niega a borrar.
+func TestDeleteSubjectWaitsForEnrollment(t *testing.T) {
+	storage, pool := newStorage(t)
+	ctx := context.Background()
+	exec(t, pool, `
+		INSERT INTO cat_courses (id, name) VALUES (1, 'Ingeniería');
+		INSERT INTO cat_semesters (id, name) VALUES (1, 'Primer semestre');
+		INSERT INTO academyc_history (id, course_id, key, name, coins) VALUES (1, 1, 'MAT1', 'Matemáticas I', 8);
+		INSERT INTO alumn (id, name, lastname1, course_id, current_semester) VALUES (1, 'Ana', 'García', 1, 1);
+	`)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("error al iniciar la transacción: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `INSERT INTO semester_course (alumn_id, semester_id, subject_id) VALUES (1, 1, 1)`); err != nil {
+		t.Fatalf("error al inscribir: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- storage.DeleteSubject(ctx, 1) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("DeleteSubject terminó sin esperar la inscripción: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("error al confirmar la inscripción: %v", err)
+	}
+	if err := <-done; !errors.Is(err, repository.ErrSubjectInUse) {
+		t.Errorf("error = %v, se esperaba ErrSubjectInUse", err)
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"alumnos/models"
+	"context"
+	"fmt"
+)
+
+func (s *PgxStorage) GetTeacherByEmail(ctx context.Context, email string) (*models.Teacher, error) {
+	ctx = WithOperation(ctx, "GetTeacherByEmail")
+	query := `
+		SELECT id, name, lastname1, COALESCE(lastname2, ''), email, password, role, created_at, updated_at
+		FROM teacher
+		WHERE email = $1;
+	`
+
+	var t models.Teacher
+	err := s.DbPool.QueryRow(ctx, query, email).Scan(
+		&t.ID,
+		&t.Name,
+		&t.Lastname1,
+		&t.Lastname2,
+		&t.Email,
+		&t.Password,
+		&t.Role,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener profesor: %w", err)
+	}
+
+	return &t, nil
+}
+
+func (s *PgxStorage) TeacherTeachesSemesterCourse(ctx context.Context, teacherID, semesterCourseID int) (bool, error) {
+	ctx = WithOperation(ctx, "TeacherTeachesSemesterCourse")
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM semester_course sc
+			JOIN teacher_subjects ts ON ts.subject_id = sc.subject_id
+			WHERE sc.id = $1 AND ts.teacher_id = $2
+		);
+	`
+
+	var teaches bool
+	if err := s.DbPool.QueryRow(ctx, query, semesterCourseID, teacherID).Scan(&teaches); err != nil {
+		return false, fmt.Errorf("error al verificar materias del profesor: %w", err)
+	}
+
+	return teaches, nil
+}
+
+func (s *PgxStorage) AssignSubjectToTeacher(ctx context.Context, teacherID, subjectID int) error {
+	ctx = WithOperation(ctx, "AssignSubjectToTeacher")
+	query := `
+		INSERT INTO teacher_subjects (teacher_id, subject_id)
+		VALUES ($1, $2)
+		ON CONFLICT (teacher_id, subject_id) DO NOTHING;
+	`
+
+	_, err := s.DbPool.Exec(ctx, query, teacherID, subjectID)
+	if err != nil {
+		return fmt.Errorf("error al asignar materia %d al profesor %d: %w", subjectID, teacherID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware abre un span de servidor por solicitud y devuelve el header
+// traceparent para que el cliente pueda buscar la traza. Solo continúa la traza
+// (y su decisión de muestreo) del traceparent entrante cuando trustParent
+// acepta la solicitud, p. ej. porque viene del proxy; el de cualquier otro
+// cliente se ignora y la traza empieza aquí con la fracción configurada. El
+// nombre definitivo y el código de estado los completa ObserveRequest cuando
+// se conoce la ruta.
+func Middleware(next http.Handler, trustParent func(*http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if trustParent != nil && trustParent(r) {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+		}
+		ctx, span := tracer().Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(w.Header()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ObserveRequest tiene la firma de api.RequestObserver: nombra el span de la
+// solicitud con el patrón de la ruta y registra el código de respuesta.
+func ObserveRequest(r *http.Request, route string, status int, _ time.Duration) {
+	span := trace.SpanFromContext(r.Context())
+	if !span.IsRecording() {
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if route != "unmatched" {
+		span.SetName(route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+	if status >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
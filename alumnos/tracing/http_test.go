@@ -0,0 +1,161 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanID  = "00f067aa0ba902b7"
+	// traceparent muestreado (flags 01) que podría enviar cualquier cliente
+	sampledParent = "00-" + parentTraceID + "-" + parentSpanID + "-01"
+)
+
+// useRecorder instala un TracerProvider que guarda los spans terminados y el
+// propagador W3C, como Setup, y los restaura al terminar la prueba.
+func useRecorder(t *testing.T, sampler sdktrace.Sampler) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler), sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestMiddlewarePropagation(t *testing.T) {
+	fromProxy := func(r *http.Request) bool { return strings.HasPrefix(r.RemoteAddr, "10.") }
+
+	tests := []struct {
+		name        string
+		trustParent func(*http.Request) bool
+		remote      string
+		wantParent  bool
+	}{
+		{"desde el proxy continúa la traza", fromProxy, "10.0.0.2:5000", true},
+		{"un cliente directo empieza otra", fromProxy, "203.0.113.7:5000", false},
+		{"sin proxies de confianza", nil, "10.0.0.2:5000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Con fracción 0 solo se muestrea lo que herede la decisión del padre
+			recorder := useRecorder(t, sdktrace.ParentBased(sdktrace.TraceIDRatioBased(0)))
+
+			var inner trace.SpanContext
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				inner = trace.SpanContextFromContext(r.Context())
+			}), tt.trustParent)
+
+			r := httptest.NewRequest("GET", "/v1/alumnos/7", nil)
+			r.RemoteAddr = tt.remote
+			r.Header.Set("traceparent", sampledParent)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			continued := inner.TraceID().String() == parentTraceID
+			if continued != tt.wantParent {
+				t.Errorf("trace_id = %s, continúa la traza del cliente: %v, se esperaba %v", inner.TraceID(), continued, tt.wantParent)
+			}
+			if inner.IsSampled() != tt.wantParent {
+				t.Errorf("muestreado = %v, se esperaba %v", inner.IsSampled(), tt.wantParent)
+			}
+			if got := rec.Header().Get("traceparent"); !strings.Contains(got, inner.TraceID().String()) {
+				t.Errorf("traceparent de la respuesta = %q, se esperaba la traza %s", got, inner.TraceID())
+			}
+
+			spans := recorder.Ended()
+			if !tt.wantParent {
+				if len(spans) != 0 {
+					t.Errorf("spans exportados = %d, se esperaba 0", len(spans))
+				}
+				return
+			}
+			if len(spans) != 1 {
+				t.Fatalf("spans exportados = %d, se esperaba 1", len(spans))
+			}
+			if got := spans[0].Parent().SpanID().String(); got != parentSpanID {
+				t.Errorf("padre = %s, se esperaba %s", got, parentSpanID)
+			}
+		})
+	}
+}
+
+func TestMiddlewareAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      string
+		status     int
+		wantName   string
+		wantRoute  bool
+		wantStatus codes.Code
+	}{
+		{"ruta conocida", "GET /v1/alumnos/{id}", http.StatusOK, "GET /v1/alumnos/{id}", true, codes.Unset},
+		{"error del servidor", "GET /v1/alumnos/{id}", http.StatusInternalServerError, "GET /v1/alumnos/{id}", true, codes.Error},
+		{"sin ruta", "unmatched", http.StatusNotFound, "GET", false, codes.Unset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useRecorder(t, sdktrace.AlwaysSample())
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ObserveRequest(r, tt.route, tt.status, 0)
+			}), nil)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/alumnos/7", nil))
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("spans exportados = %d, se esperaba 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.wantName {
+				t.Errorf("nombre = %q, se esperaba %q", span.Name(), tt.wantName)
+			}
+			if span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("tipo = %v, se esperaba server", span.SpanKind())
+			}
+			if span.Status().Code != tt.wantStatus {
+				t.Errorf("estado = %v, se esperaba %v", span.Status().Code, tt.wantStatus)
+			}
+
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+			want := map[attribute.Key]any{
+				"http.request.method":       "GET",
+				"url.path":                  "/v1/alumnos/7",
+				"http.response.status_code": int64(tt.status),
+			}
+			if tt.wantRoute {
+				want["http.route"] = tt.route
+			}
+			for key, value := range want {
+				if got, ok := attrs[key]; !ok || got.AsInterface() != value {
+					t.Errorf("%s = %v, se esperaba %v", key, got.AsInterface(), value)
+				}
+			}
+			if _, ok := attrs["http.route"]; ok && !tt.wantRoute {
+				t.Errorf("http.route = %v, no se esperaba sin ruta", attrs["http.route"].AsInterface())
+			}
+		})
+	}
+}
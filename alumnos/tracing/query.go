@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"alumnos/repository"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rowsKey guarda las filas devueltas o afectadas por la consulta.
+const rowsKey = attribute.Key("db.response.rows")
+
+// QueryTracer implementa pgx.QueryTracer: abre un span hijo de la solicitud
+// por cada consulta, nombrado con la operación del contexto (ver
+// repository.WithOperation). Los argumentos nunca se agregan porque contienen
+// datos personales.
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(strings.Join(strings.Fields(data.SQL), " ")),
+	}
+	if conn != nil {
+		attrs = append(attrs, semconv.DBNamespace(conn.Config().Database))
+	}
+
+	ctx, _ = tracer().Start(ctx, repository.Operation(ctx)+" "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, "consulta fallida")
+		return
+	}
+	span.SetAttributes(rowsKey.Int64(data.CommandTag.RowsAffected()))
+}
+
+// sqlOperation devuelve la primera palabra de la consulta en mayúsculas
+// (SELECT, INSERT, WITH...).
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Reglas de dominio para calificaciones. La escala es de 0 a 10 y cada
+// materia tiene dos parciales; el trigger update_final_grade promedia ambos.
+const (
+	MinGrade = 0.0
+	MaxGrade = 10.0
+)
+
+var AllowedPartials = []int{1, 2}
+
+// FieldError describe un problema con un campo concreto de la solicitud.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validator acumula todos los errores en lugar de detenerse en el primero.
+type Validator struct {
+	errors []FieldError
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+func (v *Validator) Add(field, message string) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: message})
+}
+
+// Check agrega el error si ok es falso y devuelve ok para encadenar reglas.
+func (v *Validator) Check(ok bool, field, message string) bool {
+	if !ok {
+		v.Add(field, message)
+	}
+	return ok
+}
+
+func (v *Validator) Valid() bool {
+	return len(v.errors) == 0
+}
+
+func (v *Validator) Errors() []FieldError {
+	return v.errors
+}
+
+// HasField indica si ya hay un error para el campo, para no consultar la base con datos inválidos.
+func (v *Validator) HasField(field string) bool {
+	for _, e := range v.errors {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) Required(field, value string) bool {
+	return v.Check(value != "", field, "es obligatorio")
+}
+
+func (v *Validator) PositiveID(field string, value int) bool {
+	return v.Check(value > 0, field, "debe ser un número positivo")
+}
+
+func (v *Validator) Grade(field string, grade *float64) bool {
+	if !v.Check(grade != nil, field, "es obligatorio") {
+		return false
+	}
+	return v.Check(*grade >= MinGrade && *grade <= MaxGrade, field,
+		fmt.Sprintf("debe estar entre %g y %g", MinGrade, MaxGrade))
+}
+
+func (v *Validator) PartialNumber(field string, partial int) bool {
+	return v.Check(slices.Contains(AllowedPartials, partial), field,
+		fmt.Sprintf("debe ser uno de %v", AllowedPartials))
+}
+
+// UniqueIDs marca los IDs repetidos dentro de una lista.
+func (v *Validator) UniqueIDs(field string, ids []int) bool {
+	seen := make(map[int]bool, len(ids))
+	ok := true
+	for i, id := range ids {
+		if seen[id] {
+			v.Add(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("el ID %d está repetido", id))
+			ok = false
+		}
+		seen[id] = true
+	}
+	return ok
+}
+
+// Catalog expone las consultas que necesitan las reglas que dependen de la base de datos.
+type Catalog interface {
+	SemesterExists(ctx context.Context, semesterID int) (bool, error)
+	CourseExists(ctx context.Context, courseID int) (bool, error)
+	// SubjectsNotInCourse devuelve los IDs que no son materias vigentes del curso.
+	SubjectsNotInCourse(ctx context.Context, courseID int, subjectIDs []int) ([]int, error)
+}
+
+// Semester valida que el semestre exista en cat_semesters.
+func (v *Validator) Semester(ctx context.Context, catalog Catalog, field string, semesterID int) error {
+	if v.HasField(field) {
+		return nil
+	}
+	exists, err := catalog.SemesterExists(ctx, semesterID)
+	if err != nil {
+		return err
+	}
+	v.Check(exists, field, fmt.Sprintf("el semestre %d no existe", semesterID))
+	return nil
+}
+
+// Course valida que la carrera exista en cat_courses.
+func (v *Validator) Course(ctx context.Context, catalog Catalog, field string, courseID int) error {
+	if v.HasField(field) {
+		return nil
+	}
+	exists, err := catalog.CourseExists(ctx, courseID)
+	if err != nil {
+		return err
+	}
+	v.Check(exists, field, fmt.Sprintf("la carrera %d no existe", courseID))
+	return nil
+}
+
+// SubjectsInCourse valida que cada materia pertenezca al plan vigente de la carrera.
+func (v *Validator) SubjectsInCourse(ctx context.Context, catalog Catalog, field string, courseID int, subjectIDs []int) error {
+	if len(subjectIDs) == 0 {
+		return nil
+	}
+	outside, err := catalog.SubjectsNotInCourse(ctx, courseID, subjectIDs)
+	if err != nil {
+		return err
+	}
+	for i, id := range subjectIDs {
+		if slices.Contains(outside, id) {
+			v.Add(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("la materia %d no pertenece a la carrera %d", id, courseID))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGrade(t *testing.T) {
+	grade := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name  string
+		grade *float64
+		want  bool
+	}{
+		{"mínimo", grade(0), true},
+		{"máximo", grade(10), true},
+		{"intermedio", grade(7.5), true},
+		{"debajo del mínimo", grade(-0.1), false},
+		{"arriba del máximo", grade(10.1), false},
+		{"ausente", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New()
+			if got := v.Grade("grade", tt.grade); got != tt.want {
+				t.Errorf("Grade = %v, se esperaba %v", got, tt.want)
+			}
+			if v.Valid() != tt.want {
+				t.Errorf("Valid = %v, se esperaba %v (errores: %v)", v.Valid(), tt.want, v.Errors())
+			}
+		})
+	}
+}
+
+func TestPartialNumber(t *testing.T) {
+	tests := []struct {
+		partial int
+		want    bool
+	}{
+		{1, true},
+		{2, true},
+		{0, false},
+		{3, false},
+		{-1, false},
+	}
+
+	for _, tt := range tests {
+		v := New()
+		if got := v.PartialNumber("partial_number", tt.partial); got != tt.want {
+			t.Errorf("PartialNumber(%d) = %v, se esperaba %v", tt.partial, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []int
+		want []FieldError
+	}{
+		{"vacía", nil, nil},
+		{"sin repetidos", []int{1, 2, 3}, nil},
+		{"un repetido", []int{1, 2, 1}, []FieldError{{"subjects[2]", "el ID 1 está repetido"}}},
+		{"cada repetición se marca", []int{4, 4, 4}, []FieldError{
+			{"subjects[1]", "el ID 4 está repetido"},
+			{"subjects[2]", "el ID 4 está repetido"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New()
+			if got := v.UniqueIDs("subjects", tt.ids); got != (tt.want == nil) {
+				t.Errorf("UniqueIDs = %v, se esperaba %v", got, tt.want == nil)
+			}
+			if !reflect.DeepEqual(v.Errors(), tt.want) {
+				t.Errorf("errores = %v, se esperaba %v", v.Errors(), tt.want)
+			}
+		})
+	}
+}
+
+// El validador reúne los errores de varios campos en una sola respuesta.
+func TestValidatorCollectsErrors(t *testing.T) {
+	grade := 11.0
+	v := New()
+	v.Required("name", "")
+	v.PositiveID("course_id", 0)
+	v.PartialNumber("partial_number", 3)
+	v.Grade("grade", &grade)
+	v.Required("lastname1", "García")
+
+	want := []FieldError{
+		{"name", "es obligatorio"},
+		{"course_id", "debe ser un número positivo"},
+		{"partial_number", "debe ser uno de [1 2]"},
+		{"grade", "debe estar entre 0 y 10"},
+	}
+	if v.Valid() {
+		t.Fatal("Valid = true, se esperaba false")
+	}
+	if !reflect.DeepEqual(v.Errors(), want) {
+		t.Errorf("errores = %v, se esperaba %v", v.Errors(), want)
+	}
+	if !v.HasField("grade") || v.HasField("lastname1") {
+		t.Errorf("HasField no coincide con los errores: %v", v.Errors())
+	}
+}
+
+// fakeCatalog cuenta las consultas para comprobar cuáles se omiten.
+type fakeCatalog struct {
+	semesters map[int]bool
+	courses   map[int]bool
+	calls     int
+	err       error
+}
+
+func (c *fakeCatalog) SemesterExists(ctx context.Context, semesterID int) (bool, error) {
+	c.calls++
+	return c.semesters[semesterID], c.err
+}
+
+func (c *fakeCatalog) CourseExists(ctx context.Context, courseID int) (bool, error) {
+	c.calls++
+	return c.courses[courseID], c.err
+}
+
+func (c *fakeCatalog) SubjectsNotInCourse(ctx context.Context, courseID int, subjectIDs []int) ([]int, error) {
+	c.calls++
+	return nil, c.err
+}
+
+func TestSemester(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		semesterID int
+		wantValid  bool
+		wantCalls  int
+	}{
+		{"existe", 1, true, 1},
+		{"no existe", 9, false, 1},
+		// Un ID inválido ya tiene error: no se consulta la base
+		{"ID inválido", 0, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := &fakeCatalog{semesters: map[int]bool{1: true}}
+			v := New()
+			v.PositiveID("semester_id", tt.semesterID)
+			if err := v.Semester(ctx, catalog, "semester_id", tt.semesterID); err != nil {
+				t.Fatalf("Semester: %v", err)
+			}
+			if v.Valid() != tt.wantValid {
+				t.Errorf("Valid = %v, se esperaba %v", v.Valid(), tt.wantValid)
+			}
+			if catalog.calls != tt.wantCalls {
+				t.Errorf("consultas = %d, se esperaba %d", catalog.calls, tt.wantCalls)
+			}
+			// Nunca se acumulan dos errores para el mismo campo
+			if len(v.Errors()) > 1 {
+				t.Errorf("errores = %v, se esperaba uno como máximo", v.Errors())
+			}
+		})
+	}
+}
+
+func TestCourseSkipsInvalidField(t *testing.T) {
+	catalog := &fakeCatalog{}
+	v := New()
+	v.PositiveID("course_id", -1)
+	if err := v.Course(context.Background(), catalog, "course_id", -1); err != nil {
+		t.Fatalf("Course: %v", err)
+	}
+	if catalog.calls != 0 {
+		t.Errorf("consultas = %d, se esperaba 0", catalog.calls)
+	}
+}
+
+func TestCatalogError(t *testing.T) {
+	catalog := &fakeCatalog{err: errors.New("base caída")}
+	v := New()
+	if err := v.Semester(context.Background(), catalog, "semester_id", 1); !errors.Is(err, catalog.err) {
+		t.Errorf("error = %v, se esperaba %v", err, catalog.err)
+	}
+	if !v.Valid() {
+		t.Errorf("un error de la base no es un error de validación: %v", v.Errors())
+	}
+}
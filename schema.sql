@@ -4,8 +4,10 @@
 CREATE TABLE IF NOT EXISTS cat_courses (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
+    curriculum_version VARCHAR(50), -- versión del plan de estudios aplicado (alumnos/curriculum/data)
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_cat_courses_name UNIQUE (name)
 );
 
 CREATE TABLE IF NOT EXISTS academyc_history (
@@ -15,7 +17,10 @@ CREATE TABLE IF NOT EXISTS academyc_history (
     name VARCHAR(255) NOT NULL, 
     coins INTEGER DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    retired_at TIMESTAMP, -- materia dada de baja del plan de estudios
+    curriculum_version VARCHAR(50), -- plan de estudios que la escribió; NULL si la creó o modificó un administrador
+    CONSTRAINT uq_academyc_history_course_key UNIQUE (course_id, key)
 );
 
 CREATE TABLE IF NOT EXISTS cat_semesters (
@@ -25,6 +30,19 @@ CREATE TABLE IF NOT EXISTS cat_semesters (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Periodo del calendario en que se cursa una materia (p. ej. 2025A), distinto
+-- del nivel del alumno que representa cat_semesters
+CREATE TABLE IF NOT EXISTS academic_periods (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_academic_periods_code UNIQUE (code),
+    CONSTRAINT chk_academic_periods_dates CHECK (end_date > start_date)
+);
+
 CREATE TABLE IF NOT EXISTS alumn (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
@@ -32,8 +50,11 @@ CREATE TABLE IF NOT EXISTS alumn (
     lastname2 VARCHAR(255),
     course_id INTEGER NOT NULL,
     current_semester INTEGER,
+    email VARCHAR(255) UNIQUE, -- opcional, solo para alumnos con acceso al sistema
+    password VARCHAR(255), -- hash bcrypt
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP -- borrado lógico; conserva el historial de calificaciones
 );
 
 
@@ -42,9 +63,12 @@ CREATE TABLE IF NOT EXISTS semester_course (
     alumn_id INTEGER NOT NULL,
     semester_id INTEGER NOT NULL,
     subject_id INTEGER NOT NULL,
+    period_id INTEGER, -- periodo en que se cursó; NULL en inscripciones anteriores a los periodos
     final_grade DOUBLE PRECISION, 
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- Una inscripción por materia y semestre; las copias repartían las calificaciones
+    CONSTRAINT uq_semester_course_enrollment UNIQUE (alumn_id, semester_id, subject_id)
 );
 
 CREATE TABLE IF NOT EXISTS partial_grades (
@@ -54,7 +78,10 @@ CREATE TABLE IF NOT EXISTS partial_grades (
     grade DOUBLE PRECISION NOT NULL, 
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    UNIQUE (semester_course_id, partial_number)
+    UNIQUE (semester_course_id, partial_number),
+    -- Mismas reglas que alumnos/validation
+    CONSTRAINT chk_partial_grades_grade CHECK (grade BETWEEN 0 AND 10),
+    CONSTRAINT chk_partial_grades_partial_number CHECK (partial_number IN (1, 2))
 );
 
 CREATE TABLE IF NOT EXISTS semester_grades (
@@ -77,12 +104,24 @@ CREATE TABLE IF NOT EXISTS teacher (
     name VARCHAR(255) NOT NULL,
     lastname1 VARCHAR(255) NOT NULL,
     lastname2 VARCHAR(255),
-    email VARCHAR(255) NOT NULL,
-    password VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    password VARCHAR(255) NOT NULL, -- hash bcrypt
+    role VARCHAR(20) NOT NULL DEFAULT 'teacher' CHECK (role IN ('teacher', 'admin')),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Materias que imparte cada profesor
+CREATE TABLE IF NOT EXISTS teacher_subjects (
+    id SERIAL PRIMARY KEY,
+    teacher_id INTEGER NOT NULL,
+    subject_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (teacher_id, subject_id),
+    FOREIGN KEY (teacher_id) REFERENCES teacher(id) ON DELETE CASCADE,
+    FOREIGN KEY (subject_id) REFERENCES academyc_history(id) ON DELETE CASCADE
+);
+
 ALTER TABLE academyc_history
 ADD CONSTRAINT fk_academyc_history_course_id
 FOREIGN KEY (course_id) REFERENCES cat_courses(id) ON DELETE CASCADE;
@@ -99,6 +138,10 @@ ALTER TABLE semester_course
 ADD CONSTRAINT fk_semester_course_alumn_id
 FOREIGN KEY (alumn_id) REFERENCES alumn(id) ON DELETE CASCADE;
 
+ALTER TABLE semester_course
+ADD CONSTRAINT fk_semester_course_period_id
+FOREIGN KEY (period_id) REFERENCES academic_periods(id);
+
 ALTER TABLE partial_grades
 ADD CONSTRAINT fk_partial_grades_semester_course
 FOREIGN KEY (semester_course_id) REFERENCES semester_course(id) ON DELETE CASCADE;
@@ -107,6 +150,40 @@ ALTER TABLE alumn
 ADD CONSTRAINT fk_current_semester_alumn_id
 FOREIGN KEY (current_semester) REFERENCES cat_semesters(id);
 
+-- Respuestas guardadas de los POST que aceptan el header Idempotency-Key;
+-- status_code NULL indica que la solicitud original sigue en curso
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope VARCHAR(255) NOT NULL, -- ruta y usuario que envió la clave
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL, -- sha256 del cuerpo de la solicitud
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
+
+-- Índices para el listado paginado de alumnos
+CREATE INDEX IF NOT EXISTS idx_alumn_lastname ON alumn (lastname1, lastname2 NULLS FIRST, name, id);
+CREATE INDEX IF NOT EXISTS idx_alumn_course_semester ON alumn (course_id, current_semester);
+CREATE INDEX IF NOT EXISTS idx_alumn_created_at ON alumn (created_at);
+
+-- Búsqueda de alumnos por nombre sin distinguir acentos ni mayúsculas
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() no es IMMUTABLE; este envoltorio permite usarlo en índices
+CREATE OR REPLACE FUNCTION alumn_search_name(name TEXT, lastname1 TEXT, lastname2 TEXT)
+RETURNS TEXT AS $$
+    SELECT lower(public.unaccent('public.unaccent', name || ' ' || lastname1 || ' ' || COALESCE(lastname2, '')));
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_alumn_search_name
+ON alumn USING gin (alumn_search_name(name, lastname1, lastname2) gin_trgm_ops);
+
 CREATE OR REPLACE FUNCTION update_final_grade()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -177,3 +254,28 @@ CREATE TRIGGER calculate_final_semester_grade
 AFTER UPDATE OF final_grade ON semester_course
 FOR EACH ROW
 EXECUTE FUNCTION update_final_semester_grade();
+
+-- Avisa a los servidores en ejecución cuando cambian los catálogos para que
+-- vacíen su caché (alumnos/catalog)
+CREATE OR REPLACE FUNCTION notify_catalog_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('catalog_changed', TG_TABLE_NAME);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER notify_cat_courses_change
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON cat_courses
+FOR EACH STATEMENT
+EXECUTE FUNCTION notify_catalog_change();
+
+CREATE OR REPLACE TRIGGER notify_academyc_history_change
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON academyc_history
+FOR EACH STATEMENT
+EXECUTE FUNCTION notify_catalog_change();
+
+CREATE OR REPLACE TRIGGER notify_cat_semesters_change
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON cat_semesters
+FOR EACH STATEMENT
+EXECUTE FUNCTION notify_catalog_change();